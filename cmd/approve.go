@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/approval"
+	"github.com/yourusername/iamctl/internal/prompt"
+)
+
+// NewApproveCommand creates the approve command
+func NewApproveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "approve <request-file>",
+		Short: "Approve a pending destructive operation",
+		Long: `Review a signed approval request created with --request-approval and add
+your signature to it. The request can then be run with 'iamctl execute'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyName, _ := cmd.Flags().GetString("signing-key")
+			if keyName == "" {
+				return fmt.Errorf("signing-key is required")
+			}
+
+			// Load the request and the approver's key
+			req, err := approval.Load(args[0])
+			if err != nil {
+				return fmt.Errorf("❌ Approval failed: %v", err)
+			}
+
+			signer, err := approval.LoadSigner(keyName)
+			if err != nil {
+				return fmt.Errorf("❌ Approval failed: %v", err)
+			}
+
+			// Show exactly what is being approved
+			fmt.Print(req.Summary())
+			if err := prompt.Confirm("Approve this request?"); err != nil {
+				return fmt.Errorf("❌ Approval cancelled: %v", err)
+			}
+
+			// Countersign and write the request back
+			if err := req.Approve(signer); err != nil {
+				return fmt.Errorf("❌ Approval failed: %v", err)
+			}
+			if err := req.Save(args[0]); err != nil {
+				return fmt.Errorf("❌ Approval failed: %v", err)
+			}
+
+			fmt.Printf("✅ Request approved. Run it with: iamctl execute %s\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().String("signing-key", "", "Name of your approval signing key (required)")
+
+	cmd.AddCommand(newApproveKeygenCommand())
+	cmd.AddCommand(newApproveTrustCommand())
+
+	return cmd
+}
+
+// newApproveKeygenCommand creates the approve keygen command
+func newApproveKeygenCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "keygen <name>",
+		Short: "Create an approval signing key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pubPath, err := approval.GenerateKey(args[0])
+			if err != nil {
+				return fmt.Errorf("❌ Key generation failed: %v", err)
+			}
+
+			fmt.Printf("✅ Signing key %q created. Share %s with the other operators, who trust it with 'iamctl approve trust'\n", args[0], pubPath)
+			return nil
+		},
+	}
+}
+
+// newApproveTrustCommand creates the approve trust command
+func newApproveTrustCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "trust <public-key-file>",
+		Short: "Trust another operator's approval key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			public, err := approval.ReadPublicKey(args[0])
+			if err != nil {
+				return fmt.Errorf("❌ Trust failed: %v", err)
+			}
+			if err := approval.Trust(public); err != nil {
+				return fmt.Errorf("❌ Trust failed: %v", err)
+			}
+
+			fmt.Printf("✅ Key %q is now trusted\n", public.Name)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/awsfile"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/session"
+	"golang.org/x/term"
+)
+
+// credentialProcessEnv is set while credential-process runs so a profile that
+// points back at itself fails instead of recursing
+const credentialProcessEnv = "IAMCTL_CREDENTIAL_PROCESS"
+
+// credentialProcessOutput is the JSON document the AWS CLI and SDKs expect
+// from a credential_process command
+type credentialProcessOutput struct {
+	Version         int    `json:"Version"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken"`
+	Expiration      string `json:"Expiration"`
+}
+
+// sessionOptions are the settings shared by credential-process and the
+// configure hook that writes its command line
+type sessionOptions struct {
+	Profile   string
+	MFASerial string
+	RoleARN   string
+	Duration  time.Duration
+}
+
+// NewCredentialProcessCommand creates the credential-process command
+func NewCredentialProcessCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "credential-process",
+		Short: "Print MFA session credentials for the AWS credential_process setting",
+		Long: `Print credentials from the profile's MFA session in the credential_process
+format used by the AWS CLI and SDKs. The session is reused from the iamctl
+cache while it is valid; otherwise an MFA code is read from the controlling
+terminal and a new session is started.
+
+Set it up with 'iamctl configure credential-process'.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := sessionOptionsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			// 1. Refuse to run inside our own credential_process
+			if os.Getenv(credentialProcessEnv) != "" {
+				return fmt.Errorf("❌ credential-process called itself; --profile must name a profile with its own credentials")
+			}
+			os.Setenv(credentialProcessEnv, "1")
+
+			// Reading an MFA code waits on the user
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+
+			// 2. Reuse the cached session or start a new one
+			entry, err := awssdk.CachedSession(ctx, opts.Profile, opts.MFASerial, opts.RoleARN, opts.Duration,
+				mfatoken.ForProfile(opts.Profile, readMFATokenFromTTY))
+			if err != nil {
+				return handleSessionErrors(err)
+			}
+
+			// 3. Emit the credentials on stdout, nothing else may be printed there
+			output, err := credentialProcessJSON(entry)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(output))
+			return err
+		},
+	}
+
+	addSessionFlags(cmd)
+
+	return cmd
+}
+
+// NewConfigureCommand creates the configure command
+func NewConfigureCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "configure",
+		Short: "Configure AWS profiles to use iamctl",
+	}
+
+	cmd.AddCommand(newConfigureCredentialProcessCommand())
+
+	return cmd
+}
+
+func newConfigureCredentialProcessCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "credential-process",
+		Short: "Add a profile to ~/.aws/config that gets MFA credentials from iamctl",
+		Long: `Add a profile to the AWS config file whose credential_process runs
+'iamctl credential-process' for --profile. Tools using the new profile get
+MFA-backed session credentials without handling MFA themselves.
+
+The new profile is named <profile>-mfa unless --name is given.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := sessionOptionsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			name, _ := cmd.Flags().GetString("name")
+
+			if name == "" {
+				name = session.ProfileName(opts.Profile) + "-mfa"
+			}
+			if name == session.ProfileName(opts.Profile) {
+				return fmt.Errorf("❌ --name must differ from --profile")
+			}
+
+			executable, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("❌ Failed to locate iamctl: %v", err)
+			}
+
+			// Edit the config file in place, keeping everything else untouched
+			configFile, err := awsfile.Load(awsfile.ConfigPath())
+			if err != nil {
+				return fmt.Errorf("❌ Configure failed: %v", err)
+			}
+
+			configureCredentialProcess(configFile, name, executable, opts, cmd.Flags().Changed("duration"))
+
+			if err := configFile.Save(); err != nil {
+				return fmt.Errorf("❌ Configure failed: %v", err)
+			}
+
+			fmt.Printf("✅ Profile %s added to %s. Use it with AWS_PROFILE=%s\n", name, configFile.Path(), name)
+			return nil
+		},
+	}
+
+	addSessionFlags(cmd)
+	cmd.Flags().String("name", "", "Name of the profile to create (defaults to <profile>-mfa)")
+
+	return cmd
+}
+
+// configureCredentialProcess points profile name at iamctl credential-process
+// for opts.Profile, carrying over the source profile's region
+func configureCredentialProcess(configFile *awsfile.File, name, executable string, opts sessionOptions, withDuration bool) {
+	args := []string{quoteArg(executable), "credential-process", "--profile", quoteArg(session.ProfileName(opts.Profile))}
+	if opts.MFASerial != "" {
+		args = append(args, "--mfa-serial", quoteArg(opts.MFASerial))
+	}
+	if opts.RoleARN != "" {
+		args = append(args, "--role-arn", quoteArg(opts.RoleARN))
+	}
+	if withDuration {
+		args = append(args, "--duration", opts.Duration.String())
+	}
+
+	section := awsfile.ProfileSection(name)
+	configFile.Set(section, "credential_process", strings.Join(args, " "))
+
+	if region, ok := configFile.Get(awsfile.ProfileSection(opts.Profile), "region"); ok {
+		if _, set := configFile.Get(section, "region"); !set {
+			configFile.Set(section, "region", region)
+		}
+	}
+}
+
+// credentialProcessJSON renders a session in the credential_process format
+func credentialProcessJSON(entry *session.Entry) ([]byte, error) {
+	return json.Marshal(credentialProcessOutput{
+		Version:         1,
+		AccessKeyID:     entry.AccessKeyID,
+		SecretAccessKey: entry.SecretAccessKey,
+		SessionToken:    entry.SessionToken,
+		Expiration:      entry.Expiration.UTC().Format(time.RFC3339),
+	})
+}
+
+// readMFATokenFromTTY prompts on the controlling terminal, since stdout
+// belongs to the calling tool and stdin may not be a terminal
+func readMFATokenFromTTY() (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", errors.New("no terminal available to read an MFA code; run 'iamctl session start' first")
+	}
+	defer tty.Close()
+
+	fmt.Fprint(tty, "iamctl: enter MFA token: ")
+	token, err := term.ReadPassword(int(tty.Fd()))
+	fmt.Fprintln(tty) // Add a newline after token input
+	if err != nil {
+		return "", err
+	}
+	return string(token), nil
+}
+
+// addSessionFlags adds the flags that select and shape an MFA session
+func addSessionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("profile", "p", "", "Profile with the long-term credentials to start sessions from")
+	cmd.Flags().String("mfa-serial", "", "MFA device serial number (defaults to your only MFA device)")
+	cmd.Flags().String("role-arn", "", "Assume this role with MFA instead of calling GetSessionToken")
+	cmd.Flags().Duration("duration", 12*time.Hour, "Session length (default 12h, or 1h with --role-arn)")
+}
+
+// sessionOptionsFromFlags reads and validates the flags from addSessionFlags
+func sessionOptionsFromFlags(cmd *cobra.Command) (sessionOptions, error) {
+	var opts sessionOptions
+	opts.Profile, _ = cmd.Flags().GetString("profile")
+	opts.MFASerial, _ = cmd.Flags().GetString("mfa-serial")
+	opts.RoleARN, _ = cmd.Flags().GetString("role-arn")
+	opts.Duration, _ = cmd.Flags().GetDuration("duration")
+
+	// Role sessions are limited to an hour unless the role allows more
+	if !cmd.Flags().Changed("duration") && opts.RoleARN != "" {
+		opts.Duration = time.Hour
+	}
+	if opts.Duration < 15*time.Minute {
+		return opts, fmt.Errorf("--duration must be at least 15m")
+	}
+	return opts, nil
+}
+
+// quoteArg quotes a credential_process argument containing spaces
+func quoteArg(arg string) string {
+	if strings.ContainsAny(arg, " \t") {
+		return `"` + arg + `"`
+	}
+	return arg
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/iamctl/internal/awsfile"
+	"github.com/yourusername/iamctl/internal/session"
+)
+
+func TestCredentialProcessJSON(t *testing.T) {
+	entry := &session.Entry{
+		AccessKeyID:     "ASIAEXAMPLE",
+		SecretAccessKey: "secret",
+		SessionToken:    "token",
+		Expiration:      time.Date(2030, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)),
+	}
+
+	output, err := credentialProcessJSON(entry)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(output, &decoded))
+	assert.Equal(t, float64(1), decoded["Version"])
+	assert.Equal(t, "ASIAEXAMPLE", decoded["AccessKeyId"])
+	assert.Equal(t, "secret", decoded["SecretAccessKey"])
+	assert.Equal(t, "token", decoded["SessionToken"])
+	assert.Equal(t, "2030-01-02T02:04:05Z", decoded["Expiration"])
+}
+
+func TestConfigureCredentialProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte("# team profiles\n[profile dev]\nregion = eu-west-1\n"), 0600))
+
+	configFile, err := awsfile.Load(path)
+	require.NoError(t, err)
+
+	opts := sessionOptions{
+		Profile:   "dev",
+		MFASerial: "arn:aws:iam::123456789012:mfa/dev",
+		Duration:  8 * time.Hour,
+	}
+	configureCredentialProcess(configFile, "dev-mfa", "/opt/my tools/iamctl", opts, true)
+	require.NoError(t, configFile.Save())
+
+	reloaded, err := awsfile.Load(path)
+	require.NoError(t, err)
+
+	process, ok := reloaded.Get("profile dev-mfa", "credential_process")
+	require.True(t, ok)
+	assert.Equal(t, `"/opt/my tools/iamctl" credential-process --profile dev --mfa-serial arn:aws:iam::123456789012:mfa/dev --duration 8h0m0s`, process)
+
+	region, _ := reloaded.Get("profile dev-mfa", "region")
+	assert.Equal(t, "eu-west-1", region)
+
+	// The source profile is left alone
+	assert.Equal(t, map[string]string{"region": "eu-west-1"}, reloaded.Section("profile dev"))
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/iam/types"
 	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/prompt"
+	"github.com/yourusername/iamctl/internal/approval"
 	"github.com/spf13/cobra"
 )
 
@@ -17,30 +20,61 @@ func NewDisableCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "disable",
 		Short: "Disable IAM access key",
-		Long:  `Instantly disable an IAM access key by its ID.`,
+		Long:  `Instantly disable an IAM access key by its ID. Requires a current MFA code.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Get profile from flags or environment
 			profile, _ := cmd.Flags().GetString("profile")
 			keyID, _ := cmd.Flags().GetString("key-id")
 			username, _ := cmd.Flags().GetString("username")
+			mfaSerial, _ := cmd.Flags().GetString("mfa-serial")
 
 			// Validate required parameters
 			if keyID == "" {
 				return fmt.Errorf("key-id is required")
 			}
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
+			// In approval mode, write a signed request instead of disabling
+			if approval.Requested(cmd) {
+				// Name the key owner explicitly; at execute time an empty
+				// username would mean the executor
+				if username == "" {
+					ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+					defer cancel()
+
+					client, err := awssdk.NewIAMClient(profile)
+					if err != nil {
+						return handleDisableAWSErrors(err)
+					}
+					user, err := awssdk.GetCurrentUser(ctx, client)
+					if err != nil {
+						return handleDisableAWSErrors(err)
+					}
+					username = *user.UserName
+				}
 
-			// Create IAM client
-			client, err := awssdk.NewIAMClient(profile)
+				path, err := approval.CreateFromFlags(cmd, "keys.disable", map[string]string{
+					"key_id":   keyID,
+					"username": username,
+				})
+				if err != nil {
+					return fmt.Errorf("❌ Approval request failed: %v", err)
+				}
+				fmt.Printf("✅ Approval request written to %s\n", path)
+				return nil
+			}
+
+			// Prove possession of a current MFA code before the API deadline starts
+			session, err := awssdk.RequireMFA(context.Background(), profile, mfaSerial, mfatoken.ForProfile(profile, prompt.MFACode))
 			if err != nil {
 				return handleDisableAWSErrors(err)
 			}
 
-			// Disable the access key
-			err = disableKey(ctx, client, keyID, username)
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			// Disable the access key with the verified session
+			err = disableKey(ctx, session.IAMClient(), keyID, username)
 			if err != nil {
 				return fmt.Errorf("❌ Disable failed: %v", sanitizeError(err))
 			}
@@ -54,10 +88,24 @@ func NewDisableCommand() *cobra.Command {
 	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
 	cmd.Flags().String("key-id", "", "ID of the access key to disable (required)")
 	cmd.Flags().String("username", "", "Username of the key owner (defaults to current user)")
+	cmd.Flags().String("mfa-serial", "", "MFA device serial number (defaults to your only MFA device)")
+	approval.AddFlags(cmd)
 
 	return cmd
 }
 
+func init() {
+	approval.Register("keys.disable", executeApprovedDisable)
+}
+
+// executeApprovedDisable disables the key named in an approved request
+func executeApprovedDisable(ctx context.Context, client *iam.Client, params map[string]string) error {
+	if params["username"] == "" {
+		return fmt.Errorf("request does not name the key owner")
+	}
+	return disableKey(ctx, client, params["key_id"], params["username"])
+}
+
 // disableKey disables an access key
 func disableKey(ctx context.Context, client *iam.Client, keyID, username string) error {
 	input := &iam.UpdateAccessKeyInput{
@@ -85,7 +133,9 @@ func handleDisableAWSErrors(err error) error {
 		return fmt.Errorf("credential error: check your AWS credentials configuration")
 	case *awssdk.PermissionError:
 		return fmt.Errorf("permission error: you don't have sufficient permissions to disable keys")
+	case *awssdk.MFAError:
+		return fmt.Errorf("❌ MFA verification failed: %v", err)
 	default:
 		return fmt.Errorf("AWS service error: cannot connect to IAM service")
 	}
-}
\ No newline at end of file
+}
@@ -1,33 +1,220 @@
 package enforce
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 	"time"
-	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/yourusername/iamctl/internal/arn"
 )
 
+type mockPolicyClient struct {
+	exists         bool
+	createErr      error
+	versions       []types.PolicyVersion
+	deletedVersion string
+	document       string
+	attached       map[string]string
+}
+
+func (m *mockPolicyClient) CreatePolicy(ctx context.Context, input *iam.CreatePolicyInput, optFns ...func(*iam.Options)) (*iam.CreatePolicyOutput, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	if m.exists {
+		return nil, &types.EntityAlreadyExistsException{Message: aws.String("exists")}
+	}
+	m.document = *input.PolicyDocument
+	return &iam.CreatePolicyOutput{}, nil
+}
+
+func (m *mockPolicyClient) ListPolicyVersions(ctx context.Context, input *iam.ListPolicyVersionsInput, optFns ...func(*iam.Options)) (*iam.ListPolicyVersionsOutput, error) {
+	return &iam.ListPolicyVersionsOutput{Versions: m.versions}, nil
+}
+
+func (m *mockPolicyClient) DeletePolicyVersion(ctx context.Context, input *iam.DeletePolicyVersionInput, optFns ...func(*iam.Options)) (*iam.DeletePolicyVersionOutput, error) {
+	m.deletedVersion = *input.VersionId
+	return &iam.DeletePolicyVersionOutput{}, nil
+}
+
+func (m *mockPolicyClient) CreatePolicyVersion(ctx context.Context, input *iam.CreatePolicyVersionInput, optFns ...func(*iam.Options)) (*iam.CreatePolicyVersionOutput, error) {
+	if input.SetAsDefault {
+		m.document = *input.PolicyDocument
+	}
+	return &iam.CreatePolicyVersionOutput{}, nil
+}
+
+func (m *mockPolicyClient) ListUsers(ctx context.Context, input *iam.ListUsersInput, optFns ...func(*iam.Options)) (*iam.ListUsersOutput, error) {
+	// Return one user per page so callers must paginate
+	if input.Marker == nil {
+		return &iam.ListUsersOutput{Users: []types.User{{UserName: aws.String("alice")}}, IsTruncated: true, Marker: aws.String("bob")}, nil
+	}
+	return &iam.ListUsersOutput{Users: []types.User{{UserName: aws.String("bob")}}}, nil
+}
+
+func (m *mockPolicyClient) AttachUserPolicy(ctx context.Context, input *iam.AttachUserPolicyInput, optFns ...func(*iam.Options)) (*iam.AttachUserPolicyOutput, error) {
+	if m.attached == nil {
+		m.attached = map[string]string{}
+	}
+	m.attached[*input.UserName] = *input.PolicyArn
+	return &iam.AttachUserPolicyOutput{}, nil
+}
+
+// TestMFAPolicyDocument checks the policy leaves users without MFA able to
+// enrol a device
+func TestMFAPolicyDocument(t *testing.T) {
+	document, err := mfaPolicyDocument(arn.PartitionAWS, defaultMFAExemptActions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed policyDocument
+	if err := json.Unmarshal([]byte(document), &parsed); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	deny := parsed.Statement[len(parsed.Statement)-1]
+	if deny.Effect != "Deny" || len(deny.Action) != 0 || deny.Resource != "*" {
+		t.Fatalf("deny statement = %+v", deny)
+	}
+	if deny.Condition["BoolIfExists"]["aws:MultiFactorAuthPresent"] != "false" {
+		t.Errorf("condition = %v", deny.Condition)
+	}
+	for _, action := range append(enrollmentActions, "iam:ChangePassword", "iam:ListVirtualMFADevices") {
+		if !containsAction(deny.NotAction, action) {
+			t.Errorf("NotAction %v is missing %s", deny.NotAction, action)
+		}
+	}
+	if containsAction(deny.NotAction, "iam:DeactivateMFADevice") {
+		t.Error("Deactivating MFA must require MFA")
+	}
+
+	// Users may delete only their own virtual devices, including the ones
+	// a failed 'mfa enable' leaves behind
+	for _, resource := range []string{"arn:aws:iam::*:mfa/${aws:username}", "arn:aws:iam::*:mfa/iamctl-${aws:username}-*"} {
+		if !hasStatement(parsed, "iam:DeleteVirtualMFADevice", resource) {
+			t.Errorf("no statement allows iam:DeleteVirtualMFADevice on %s", resource)
+		}
+	}
+
+	// Resources follow the caller's partition
+	document, err = mfaPolicyDocument(arn.PartitionChina, defaultMFAExemptActions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(document, `"arn:aws-cn:iam::*:user/${aws:username}"`) || strings.Contains(document, "arn:aws:") {
+		t.Errorf("document = %s", document)
+	}
+
+	// Custom lists are deduplicated and sorted so reruns are stable
+	document, err = mfaPolicyDocument(arn.PartitionAWS, []string{"sts:GetSessionToken", " iam:ListMFADevices", "IAM:listmfadevices"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(document, `"NotAction": [
+        "iam:ListMFADevices",
+        "sts:GetSessionToken"
+      ]`) {
+		t.Errorf("document = %s", document)
+	}
+
+	for _, exempt := range [][]string{nil, {"*"}, {"iam"}, {"iam:Get User"}} {
+		if _, err := mfaPolicyDocument(arn.PartitionAWS, exempt); err == nil {
+			t.Errorf("Expected %q to be rejected", exempt)
+		}
+	}
+}
+
+// hasStatement reports whether document allows action on resource
+func hasStatement(document policyDocument, action, resource string) bool {
+	for _, statement := range document.Statement {
+		if statement.Effect == "Allow" && statement.Resource == resource && containsAction(statement.Action, action) {
+			return true
+		}
+	}
+	return false
+}
+
 // TestEnforceMFAPolicy tests the MFA policy enforcement
 func TestEnforceMFAPolicy(t *testing.T) {
-	// Test with a timeout context
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
-	_ = ctx // Explicitly mark as used
 
-	// This is a placeholder test since we can't easily mock the IAM client interface
-	// In a real implementation, you would use a library like https://github.com/aws/aws-sdk-go-v2/tree/main/service/iam/iamiface
-	// or create a proper mock that implements all required methods
-	t.Skip("Skipping test due to complexity of mocking IAM client interface")
+	// A new policy is attached by its ARN in the caller's partition and
+	// account
+	caller, err := arn.Parse("arn:aws-us-gov:iam::123456789012:user/admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &mockPolicyClient{}
+	if err := putMFAPolicy(ctx, client, caller, defaultMFAExemptActions); err != nil {
+		t.Fatal(err)
+	}
+	want := "arn:aws-us-gov:iam::123456789012:policy/EnforceMFA"
+	if !strings.Contains(client.document, "DenyAllExceptListedIfNoMFA") || client.attached["alice"] != want || client.attached["bob"] != want {
+		t.Errorf("document = %q, attached = %v", client.document, client.attached)
+	}
+
+	// An existing policy gets a new default version, dropping the oldest
+	// non-default version when IAM's limit of five is reached
+	day := func(d int) *time.Time {
+		date := time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	client = &mockPolicyClient{exists: true, versions: []types.PolicyVersion{
+		{VersionId: aws.String("v1"), CreateDate: day(1), IsDefaultVersion: true},
+		{VersionId: aws.String("v3"), CreateDate: day(3)},
+		{VersionId: aws.String("v2"), CreateDate: day(2)},
+		{VersionId: aws.String("v4"), CreateDate: day(4)},
+		{VersionId: aws.String("v5"), CreateDate: day(5)},
+	}}
+	if err := putMFAPolicy(ctx, client, caller, []string{"sts:GetSessionToken"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(client.document, `"NotAction": [
+        "sts:GetSessionToken"
+      ]`) || client.deletedVersion != "v2" || client.attached["alice"] != want {
+		t.Errorf("document = %q, deleted = %q, attached = %v", client.document, client.deletedVersion, client.attached)
+	}
 }
 
 // TestApplySecurityPolicies tests the security policy application
 func TestApplySecurityPolicies(t *testing.T) {
-	// Test with a timeout context
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
-	_ = ctx // Explicitly mark as used
 
-	// This is a placeholder test since we can't easily mock the IAM client interface
-	// In a real implementation, you would use a library like https://github.com/aws/aws-sdk-go-v2/tree/main/service/iam/iamiface
-	// or create a proper mock that implements all required methods
-	t.Skip("Skipping test due to complexity of mocking IAM client interface")
-}
\ No newline at end of file
+	caller, err := arn.Parse("arn:aws-cn:sts::123456789012:assumed-role/Admin/alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &mockPolicyClient{}
+	if err := putSecurityPolicies(ctx, client, caller); err != nil {
+		t.Fatal(err)
+	}
+	if want := "arn:aws-cn:iam::123456789012:policy/EnforceRecentMFA"; client.attached["bob"] != want {
+		t.Errorf("attached = %v, want %s", client.attached, want)
+	}
+
+	// Existing policies get the current document as their default version
+	client = &mockPolicyClient{exists: true}
+	if err := putSecurityPolicies(ctx, client, caller); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(client.document, `"aws:MultiFactorAuthAge": "43200"`) {
+		t.Errorf("document = %q, want the recent MFA policy", client.document)
+	}
+
+	// A failed create is reported and nothing is attached
+	client = &mockPolicyClient{createErr: errors.New("access denied")}
+	if err := putSecurityPolicies(ctx, client, caller); err == nil {
+		t.Error("expected a failed CreatePolicy to be returned")
+	}
+	if len(client.attached) != 0 {
+		t.Errorf("attached = %v, want nothing after a failed create", client.attached)
+	}
+}
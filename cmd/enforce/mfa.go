@@ -2,37 +2,133 @@ package enforce
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
-	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/approval"
+	"github.com/yourusername/iamctl/internal/arn"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/config"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/prompt"
 )
 
+// mfaPolicyName is the customer managed policy enforce mfa maintains
+const mfaPolicyName = "EnforceMFA"
+
+// defaultMFAExemptActions are the calls a user without MFA may still make:
+// enough to enrol a virtual MFA device, change their own password and get
+// an MFA session
+var defaultMFAExemptActions = []string{
+	"iam:ChangePassword",
+	"iam:CreateVirtualMFADevice",
+	"iam:DeleteVirtualMFADevice",
+	"iam:EnableMFADevice",
+	"iam:GetAccountPasswordPolicy",
+	"iam:GetMFADevice",
+	"iam:GetUser",
+	"iam:ListMFADevices",
+	"iam:ListVirtualMFADevices",
+	"iam:ResyncMFADevice",
+	"sts:GetSessionToken",
+}
+
+// enrollmentActions must stay exempt for a user without MFA to enrol a
+// device; leaving one out locks them out. DeleteVirtualMFADevice removes the
+// device a failed enrolment leaves behind, which would otherwise block the
+// next attempt.
+var enrollmentActions = []string{
+	"iam:CreateVirtualMFADevice",
+	"iam:DeleteVirtualMFADevice",
+	"iam:EnableMFADevice",
+	"sts:GetSessionToken",
+}
+
+// actionPattern matches a single IAM action such as iam:GetUser or s3:Get*
+var actionPattern = regexp.MustCompile(`^[A-Za-z0-9-]+:[A-Za-z0-9*]+$`)
+
 // NewMFACommand creates the enforce MFA command
 func NewMFACommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "mfa",
 		Short: "Enforce MFA policy for all users",
-		Long:  `Create and apply an IAM policy that enforces MFA for all users in the account.`,
+		Long: `Create and apply an IAM policy that enforces MFA for all users in the account.
+
+The policy denies every action made without MFA except the ones a user needs
+to comply: viewing the password policy and their MFA devices, creating,
+enabling and deleting their own virtual MFA device, changing their own
+password and calling sts:GetSessionToken. It also allows those self-service
+calls on the user's own resources. Override the exempt actions with --exempt-action or
+enforce.mfa_exempt_actions in config.yaml; an exempt action still needs an
+allow from another policy. Running it again updates the existing policy.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Get profile from flags or environment
 			profile, _ := cmd.Flags().GetString("profile")
+			mfaSerial, _ := cmd.Flags().GetString("mfa-serial")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
+			exempt, err := mfaExemptActions(cmd)
+			if err != nil {
+				return fmt.Errorf("❌ Enforcement failed: %v", err)
+			}
+
+			if dryRun {
+				ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+				defer cancel()
+
+				// The document depends only on the caller's partition,
+				// which needs no MFA to look up
+				client, err := awssdk.NewIAMClient(profile)
+				if err != nil {
+					return fmt.Errorf("❌ Enforcement failed: Invalid credentials")
+				}
+				caller, err := callerIdentity(ctx, client)
+				if err != nil {
+					return fmt.Errorf("❌ Enforcement failed: Invalid credentials")
+				}
+				document, err := mfaPolicyDocument(caller.Partition, exempt)
+				if err != nil {
+					return fmt.Errorf("❌ Enforcement failed: %v", err)
+				}
+				fmt.Println(document)
+				return nil
+			}
 
-			// Create IAM client
-			client, err := awssdk.NewIAMClient(profile)
+			// In approval mode, write a signed request instead of enforcing
+			if approval.Requested(cmd) {
+				path, err := approval.CreateFromFlags(cmd, "enforce.mfa", map[string]string{
+					"exempt_actions": strings.Join(exempt, ","),
+				})
+				if err != nil {
+					return fmt.Errorf("❌ Approval request failed: %v", err)
+				}
+				fmt.Printf("✅ Approval request written to %s\n", path)
+				return nil
+			}
+
+			// Prove possession of a current MFA code before the API deadline starts
+			session, err := awssdk.RequireMFA(context.Background(), profile, mfaSerial, mfatoken.ForProfile(profile, prompt.MFACode))
 			if err != nil {
 				return fmt.Errorf("❌ Enforcement failed: Invalid credentials")
 			}
 
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+			client := session.IAMClient()
+
 			// Enforce MFA policy
-			err = enforceMFAPolicy(ctx, client)
+			err = enforceMFAPolicy(ctx, client, exempt)
 			if err != nil {
 				return fmt.Errorf("❌ Enforcement failed: Invalid credentials")
 			}
@@ -44,51 +140,309 @@ func NewMFACommand() *cobra.Command {
 
 	// Add flags
 	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().String("mfa-serial", "", "MFA device serial number (defaults to your only MFA device)")
+	cmd.Flags().StringSlice("exempt-action", nil, "Action allowed without MFA, repeatable; replaces the built-in list (default enforce.mfa_exempt_actions in config.yaml)")
+	cmd.Flags().Bool("dry-run", false, "Print the policy document without applying it")
+	approval.AddFlags(cmd)
 
 	return cmd
 }
 
-// enforceMFAPolicy creates and applies an MFA enforcement policy
-func enforceMFAPolicy(ctx context.Context, client *iam.Client) error {
-	// Define the MFA enforcement policy document
-	policyDocument := `{ "Version": "2012-10-17", "Statement": [ { "Effect": "Deny", "Action": "*", "Resource": "*", "Condition": { "BoolIfExists": { "aws:MultiFactorAuthPresent": "false" } } } ] }`
+func init() {
+	approval.Register("enforce.mfa", func(ctx context.Context, client *iam.Client, params map[string]string) error {
+		exempt := defaultMFAExemptActions
+		if params["exempt_actions"] != "" {
+			exempt = strings.Split(params["exempt_actions"], ",")
+		}
+		return enforceMFAPolicy(ctx, client, exempt)
+	})
+}
+
+// mfaExemptActions returns the actions allowed without MFA from
+// --exempt-action, config.yaml or the built-in list, in that order
+func mfaExemptActions(cmd *cobra.Command) ([]string, error) {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	exempt := defaultMFAExemptActions
+	if len(settings.Enforce.MFAExemptActions) > 0 {
+		exempt = settings.Enforce.MFAExemptActions
+	}
+	if cmd.Flags().Changed("exempt-action") {
+		exempt, _ = cmd.Flags().GetStringSlice("exempt-action")
+	}
+
+	exempt, err = normalizeActions(exempt)
+	if err != nil {
+		return nil, err
+	}
+	for _, action := range enrollmentActions {
+		if !containsAction(exempt, action) {
+			fmt.Fprintf(os.Stderr, "Warning: %s is not exempt, so users without MFA cannot enrol a device\n", action)
+		}
+	}
+	return exempt, nil
+}
+
+// normalizeActions validates actions and returns them deduplicated and
+// sorted, so reruns produce the same document
+func normalizeActions(actions []string) ([]string, error) {
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("at least one exempt action is required")
+	}
+
+	seen := map[string]bool{}
+	var normalized []string
+	for _, action := range actions {
+		action = strings.TrimSpace(action)
+		if !actionPattern.MatchString(action) {
+			return nil, fmt.Errorf("invalid action %q: expected service:Action", action)
+		}
+		if !seen[strings.ToLower(action)] {
+			seen[strings.ToLower(action)] = true
+			normalized = append(normalized, action)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// containsAction reports whether actions includes action, ignoring case
+// as IAM does
+func containsAction(actions []string, action string) bool {
+	for _, a := range actions {
+		if strings.EqualFold(a, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyStatement is a statement of an IAM policy document
+type policyStatement struct {
+	Sid       string
+	Effect    string
+	Action    []string `json:",omitempty"`
+	NotAction []string `json:",omitempty"`
+	Resource  string
+	Condition map[string]map[string]string `json:",omitempty"`
+}
+
+// policyDocument is an IAM policy document
+type policyDocument struct {
+	Version   string
+	Statement []policyStatement
+}
+
+// mfaPolicyDocument builds the self-service MFA policy: users may manage
+// their own password and MFA devices, and everything but the exempt actions
+// is denied until they sign in with MFA. Resources use the caller's
+// partition.
+func mfaPolicyDocument(partition string, exempt []string) (string, error) {
+	notAction, err := normalizeActions(exempt)
+	if err != nil {
+		return "", err
+	}
+
+	ownUser, err := arn.Build(partition, "iam", "", arn.AnyAccount, "user/${aws:username}")
+	if err != nil {
+		return "", err
+	}
+	mfaDevices, err := arn.Build(partition, "iam", "", arn.AnyAccount, "mfa/*")
+	if err != nil {
+		return "", err
+	}
+	// Users may only delete their own devices: one named after them, or
+	// one with the iamctl-<user>-<timestamp> name 'mfa enable' defaults to
+	ownDevice, err := arn.Build(partition, "iam", "", arn.AnyAccount, "mfa/${aws:username}")
+	if err != nil {
+		return "", err
+	}
+	ownIamctlDevices, err := arn.Build(partition, "iam", "", arn.AnyAccount, "mfa/iamctl-${aws:username}-*")
+	if err != nil {
+		return "", err
+	}
+	document := policyDocument{
+		Version: "2012-10-17",
+		Statement: []policyStatement{
+			{
+				Sid:      "AllowViewAccountInfo",
+				Effect:   "Allow",
+				Action:   []string{"iam:GetAccountPasswordPolicy", "iam:ListVirtualMFADevices"},
+				Resource: "*",
+			},
+			{
+				Sid:      "AllowManageOwnPasswords",
+				Effect:   "Allow",
+				Action:   []string{"iam:ChangePassword", "iam:GetUser"},
+				Resource: ownUser,
+			},
+			{
+				Sid:      "AllowManageOwnVirtualMFADevice",
+				Effect:   "Allow",
+				Action:   []string{"iam:CreateVirtualMFADevice"},
+				Resource: mfaDevices,
+			},
+			{
+				Sid:      "AllowDeleteOwnVirtualMFADevice",
+				Effect:   "Allow",
+				Action:   []string{"iam:DeleteVirtualMFADevice"},
+				Resource: ownDevice,
+			},
+			{
+				Sid:      "AllowDeleteOwnIamctlVirtualMFADevice",
+				Effect:   "Allow",
+				Action:   []string{"iam:DeleteVirtualMFADevice"},
+				Resource: ownIamctlDevices,
+			},
+			{
+				Sid:      "AllowManageOwnUserMFA",
+				Effect:   "Allow",
+				Action:   []string{"iam:DeactivateMFADevice", "iam:EnableMFADevice", "iam:GetMFADevice", "iam:ListMFADevices", "iam:ResyncMFADevice"},
+				Resource: ownUser,
+			},
+			{
+				Sid:       "DenyAllExceptListedIfNoMFA",
+				Effect:    "Deny",
+				NotAction: notAction,
+				Resource:  "*",
+				Condition: map[string]map[string]string{
+					"BoolIfExists": {"aws:MultiFactorAuthPresent": "false"},
+				},
+			},
+		},
+	}
 
-	// Create the policy
-	policyName := "EnforceMFA"
-	createPolicyInput := &iam.CreatePolicyInput{
-		PolicyName:     aws.String(policyName),
-		PolicyDocument: aws.String(policyDocument),
-		Description:    aws.String("Policy to enforce MFA for all users"),
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode policy: %w", err)
 	}
+	return string(data), nil
+}
 
-	_, err := client.CreatePolicy(ctx, createPolicyInput)
+// policyAPI is the subset of the IAM client used to maintain and attach the
+// enforcement policies
+type policyAPI interface {
+	CreatePolicy(context.Context, *iam.CreatePolicyInput, ...func(*iam.Options)) (*iam.CreatePolicyOutput, error)
+	ListPolicyVersions(context.Context, *iam.ListPolicyVersionsInput, ...func(*iam.Options)) (*iam.ListPolicyVersionsOutput, error)
+	DeletePolicyVersion(context.Context, *iam.DeletePolicyVersionInput, ...func(*iam.Options)) (*iam.DeletePolicyVersionOutput, error)
+	CreatePolicyVersion(context.Context, *iam.CreatePolicyVersionInput, ...func(*iam.Options)) (*iam.CreatePolicyVersionOutput, error)
+	ListUsers(context.Context, *iam.ListUsersInput, ...func(*iam.Options)) (*iam.ListUsersOutput, error)
+	AttachUserPolicy(context.Context, *iam.AttachUserPolicyInput, ...func(*iam.Options)) (*iam.AttachUserPolicyOutput, error)
+}
+
+// callerIdentity looks up the partition and account ID of the client's
+// credentials
+func callerIdentity(ctx context.Context, client *iam.Client) (arn.ARN, error) {
+	return awssdk.CallerIdentity(ctx, awssdk.STSClient(client))
+}
+
+// enforceMFAPolicy creates or updates the MFA enforcement policy and
+// attaches it to every user
+func enforceMFAPolicy(ctx context.Context, client *iam.Client, exempt []string) error {
+	caller, err := callerIdentity(ctx, client)
 	if err != nil {
-		// If policy already exists, we'll attach it instead
-		// In a real implementation, you might want to handle this more gracefully
-		// For now, we'll just continue
+		return err
 	}
+	return putMFAPolicy(ctx, client, caller, exempt)
+}
 
-	// Attach the policy to all users
-	// First, list all users
-	listUsersInput := &iam.ListUsersInput{}
-	listUsersOutput, err := client.ListUsers(ctx, listUsersInput)
+// putMFAPolicy maintains and attaches the MFA policy in the caller's
+// account
+func putMFAPolicy(ctx context.Context, client policyAPI, caller arn.ARN, exempt []string) error {
+	document, err := mfaPolicyDocument(caller.Partition, exempt)
+	if err != nil {
+		return err
+	}
+	policyARN, err := putPolicy(ctx, client, caller, mfaPolicyName, "Policy to enforce MFA for all users", document)
 	if err != nil {
-		return fmt.Errorf("failed to list users: %w", err)
+		return err
 	}
 
 	// Attach policy to each user
-	for _, user := range listUsersOutput.Users {
-		attachUserPolicyInput := &iam.AttachUserPolicyInput{
-			UserName:  user.UserName,
-			PolicyArn: aws.String(fmt.Sprintf("arn:aws:iam::%s:policy/%s", "aws", policyName)), // This would need to be the actual account ID
+	paginator := iam.NewListUsersPaginator(client, &iam.ListUsersInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list users: %w", err)
 		}
 
-		_, err := client.AttachUserPolicy(ctx, attachUserPolicyInput)
-		if err != nil {
-			// Log error but continue with other users
-			fmt.Printf("Warning: Failed to attach policy to user %s: %v\n", *user.UserName, err)
+		for _, user := range page.Users {
+			attachUserPolicyInput := &iam.AttachUserPolicyInput{
+				UserName:  user.UserName,
+				PolicyArn: aws.String(policyARN),
+			}
+
+			_, err := client.AttachUserPolicy(ctx, attachUserPolicyInput)
+			if err != nil {
+				// Log error but continue with other users
+				fmt.Printf("Warning: Failed to attach policy to user %s: %v\n", *user.UserName, err)
+			}
 		}
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// putPolicy creates a customer managed policy, or makes document the
+// default version of an existing one so that reruns replace stale
+// statements. It returns the policy ARN in the caller's account.
+func putPolicy(ctx context.Context, client policyAPI, caller arn.ARN, name, description, document string) (string, error) {
+	policyARN, err := arn.Build(caller.Partition, "iam", "", caller.AccountID, "policy/"+name)
+	if err != nil {
+		return "", err
+	}
+
+	// 1. Create the policy
+	_, err = client.CreatePolicy(ctx, &iam.CreatePolicyInput{
+		PolicyName:     aws.String(name),
+		PolicyDocument: aws.String(document),
+		Description:    aws.String(description),
+	})
+	if err == nil {
+		return policyARN, nil
+	}
+	var exists *types.EntityAlreadyExistsException
+	if !errors.As(err, &exists) {
+		return "", fmt.Errorf("failed to create policy %s: %w", name, err)
+	}
+
+	// 2. Make room: IAM keeps at most five versions
+	versions, err := client.ListPolicyVersions(ctx, &iam.ListPolicyVersionsInput{PolicyArn: aws.String(policyARN)})
+	if err != nil {
+		return "", fmt.Errorf("failed to list versions of %s: %w", name, err)
+	}
+	if len(versions.Versions) >= 5 {
+		var oldest *types.PolicyVersion
+		for i, version := range versions.Versions {
+			if version.IsDefaultVersion || version.CreateDate == nil {
+				continue
+			}
+			if oldest == nil || version.CreateDate.Before(*oldest.CreateDate) {
+				oldest = &versions.Versions[i]
+			}
+		}
+		if oldest != nil {
+			_, err := client.DeletePolicyVersion(ctx, &iam.DeletePolicyVersionInput{
+				PolicyArn: aws.String(policyARN),
+				VersionId: oldest.VersionId,
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to delete version %s of %s: %w", aws.ToString(oldest.VersionId), name, err)
+			}
+		}
+	}
+
+	// 3. Replace the document
+	_, err = client.CreatePolicyVersion(ctx, &iam.CreatePolicyVersionInput{
+		PolicyArn:      aws.String(policyARN),
+		PolicyDocument: aws.String(document),
+		SetAsDefault:   true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to update policy %s: %w", name, err)
+	}
+	return policyARN, nil
+}
@@ -2,13 +2,19 @@ package enforce
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
-	awssdk "github.com/yourusername/iamctl/internal/aws"
 	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/approval"
+	"github.com/yourusername/iamctl/internal/arn"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/prompt"
 )
 
 // NewPolicyCommand creates the enforce policy command
@@ -16,21 +22,37 @@ func NewPolicyCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "policy",
 		Short: "Apply least-privilege security policies",
-		Long:  `Apply least-privilege security policies to enforce key and MFA rotation.`,
+		Long: `Apply least-privilege security policies: credential changes (access keys,
+console passwords and MFA devices) require an MFA sign-in from the last 12 hours.
+
+IAM policies cannot check the age of a key or MFA device; use 'iamctl status'
+and 'iamctl mfa report' to find credentials that are due for rotation.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Get profile from flags or environment
 			profile, _ := cmd.Flags().GetString("profile")
+			mfaSerial, _ := cmd.Flags().GetString("mfa-serial")
+
+			// In approval mode, write a signed request instead of enforcing
+			if approval.Requested(cmd) {
+				path, err := approval.CreateFromFlags(cmd, "enforce.policy", map[string]string{})
+				if err != nil {
+					return fmt.Errorf("❌ Approval request failed: %v", err)
+				}
+				fmt.Printf("✅ Approval request written to %s\n", path)
+				return nil
+			}
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
-
-			// Create IAM client
-			client, err := awssdk.NewIAMClient(profile)
+			// Prove possession of a current MFA code before the API deadline starts
+			session, err := awssdk.RequireMFA(context.Background(), profile, mfaSerial, mfatoken.ForProfile(profile, prompt.MFACode))
 			if err != nil {
 				return fmt.Errorf("❌ Enforcement failed: Invalid credentials")
 			}
 
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+			client := session.IAMClient()
+
 			// Apply security policies
 			err = applySecurityPolicies(ctx, client)
 			if err != nil {
@@ -44,80 +66,109 @@ func NewPolicyCommand() *cobra.Command {
 
 	// Add flags
 	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().String("mfa-serial", "", "MFA device serial number (defaults to your only MFA device)")
+	approval.AddFlags(cmd)
 
 	return cmd
 }
 
+func init() {
+	approval.Register("enforce.policy", func(ctx context.Context, client *iam.Client, params map[string]string) error {
+		return applySecurityPolicies(ctx, client)
+	})
+}
+
 // applySecurityPolicies applies least-privilege security policies
 func applySecurityPolicies(ctx context.Context, client *iam.Client) error {
-	// Define the key rotation policy document
-	keyRotationPolicyDocument := `{ "Version": "2012-10-17", "Statement": [ { "Effect": "Deny", "Action": [ "iam:CreateAccessKey", "iam:UpdateAccessKey" ], "Resource": "arn:aws:iam::*:user/${aws:username}", "Condition": { "DateLessThan": { "aws:CurrentTime": "${aws:username}-key-last-rotated+90d" } } } ] }`
-
-	// Define the MFA rotation policy document
-	mfaRotationPolicyDocument := `{ "Version": "2012-10-17", "Statement": [ { "Effect": "Deny", "Action": "*", "Resource": "*", "Condition": { "DateLessThan": { "aws:MultiFactorAuthAge": "90" } } } ] }`
-
-	// Create the key rotation policy
-	keyPolicyName := "EnforceKeyRotation"
-	createKeyPolicyInput := &iam.CreatePolicyInput{
-		PolicyName:     aws.String(keyPolicyName),
-		PolicyDocument: aws.String(keyRotationPolicyDocument),
-		Description:    aws.String("Policy to enforce key rotation every 90 days"),
-	}
-
-	_, err := client.CreatePolicy(ctx, createKeyPolicyInput)
+	caller, err := callerIdentity(ctx, client)
 	if err != nil {
-		// If policy already exists, we'll attach it instead
-		// In a real implementation, you might want to handle this more gracefully
+		return err
 	}
+	return putSecurityPolicies(ctx, client, caller)
+}
 
-	// Create the MFA rotation policy
-	mfaPolicyName := "EnforceMFARotation"
-	createMFAPolicyInput := &iam.CreatePolicyInput{
-		PolicyName:     aws.String(mfaPolicyName),
-		PolicyDocument: aws.String(mfaRotationPolicyDocument),
-		Description:    aws.String("Policy to enforce MFA rotation every 90 days"),
-	}
+// recentMFAPolicyName is the customer managed policy enforce policy maintains
+const recentMFAPolicyName = "EnforceRecentMFA"
+
+// maxMFAAge is how long after signing in with MFA a user may still change
+// credentials. It matches the default length of an iamctl session.
+const maxMFAAge = 12 * time.Hour
+
+// credentialActions create or replace the credentials of an IAM user
+var credentialActions = []string{
+	"iam:CreateAccessKey",
+	"iam:CreateLoginProfile",
+	"iam:DeactivateMFADevice",
+	"iam:DeleteAccessKey",
+	"iam:UpdateAccessKey",
+	"iam:UpdateLoginProfile",
+}
 
-	_, err = client.CreatePolicy(ctx, createMFAPolicyInput)
+// recentMFAPolicyDocument builds a policy that denies credential changes
+// from a session whose MFA sign-in is older than maxMFAAge. Sessions without
+// MFA have no aws:MultiFactorAuthAge and are left to the EnforceMFA policy.
+//
+// IAM has no condition key for the age of an access key or MFA device, so
+// rotation itself is checked by 'iamctl status' and 'iamctl mfa report'
+// rather than by policy.
+func recentMFAPolicyDocument() (string, error) {
+	document := policyDocument{
+		Version: "2012-10-17",
+		Statement: []policyStatement{
+			{
+				Sid:      "DenyCredentialChangesWithoutRecentMFA",
+				Effect:   "Deny",
+				Action:   credentialActions,
+				Resource: "*",
+				Condition: map[string]map[string]string{
+					"NumericGreaterThan": {"aws:MultiFactorAuthAge": strconv.Itoa(int(maxMFAAge.Seconds()))},
+				},
+			},
+		},
+	}
+	data, err := json.MarshalIndent(document, "", "  ")
 	if err != nil {
-		// If policy already exists, we'll attach it instead
-		// In a real implementation, you might want to handle this more gracefully
+		return "", err
 	}
+	return string(data), nil
+}
 
-	// Attach policies to all users
-	// First, list all users
-	listUsersInput := &iam.ListUsersInput{}
-	listUsersOutput, err := client.ListUsers(ctx, listUsersInput)
+// putSecurityPolicies creates and attaches the security policies in the
+// caller's account
+func putSecurityPolicies(ctx context.Context, client policyAPI, caller arn.ARN) error {
+	document, err := recentMFAPolicyDocument()
 	if err != nil {
-		return fmt.Errorf("failed to list users: %w", err)
+		return err
 	}
 
-	// Attach policies to each user
-	for _, user := range listUsersOutput.Users {
-		// Attach key rotation policy
-		attachKeyPolicyInput := &iam.AttachUserPolicyInput{
-			UserName:  user.UserName,
-			PolicyArn: aws.String(fmt.Sprintf("arn:aws:iam::%s:policy/%s", "aws", keyPolicyName)), // This would need to be the actual account ID
-		}
+	// Create the policy, or bring an existing one up to date
+	policyARN, err := putPolicy(ctx, client, caller, recentMFAPolicyName,
+		"Policy to require a recent MFA sign-in for credential changes", document)
+	if err != nil {
+		return err
+	}
 
-		_, err := client.AttachUserPolicy(ctx, attachKeyPolicyInput)
+	// Attach policy to each user
+	paginator := iam.NewListUsersPaginator(client, &iam.ListUsersInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
 		if err != nil {
-			// Log error but continue with other users
-			fmt.Printf("Warning: Failed to attach key rotation policy to user %s: %v\n", *user.UserName, err)
+			return fmt.Errorf("failed to list users: %w", err)
 		}
 
-		// Attach MFA rotation policy
-		attachMFAPolicyInput := &iam.AttachUserPolicyInput{
-			UserName:  user.UserName,
-			PolicyArn: aws.String(fmt.Sprintf("arn:aws:iam::%s:policy/%s", "aws", mfaPolicyName)), // This would need to be the actual account ID
-		}
+		for _, user := range page.Users {
+			attachUserPolicyInput := &iam.AttachUserPolicyInput{
+				UserName:  user.UserName,
+				PolicyArn: aws.String(policyARN),
+			}
 
-		_, err = client.AttachUserPolicy(ctx, attachMFAPolicyInput)
-		if err != nil {
-			// Log error but continue with other users
-			fmt.Printf("Warning: Failed to attach MFA rotation policy to user %s: %v\n", *user.UserName, err)
+			_, err := client.AttachUserPolicy(ctx, attachUserPolicyInput)
+			if err != nil {
+				// Log error but continue with other users
+				fmt.Printf("Warning: Failed to attach policy to user %s: %v\n", *user.UserName, err)
+			}
 		}
 	}
 
 	return nil
-}
\ No newline at end of file
+}
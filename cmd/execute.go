@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/approval"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/prompt"
+)
+
+// NewExecuteCommand creates the execute command
+func NewExecuteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "execute <request-file>",
+		Short: "Run an approved destructive operation",
+		Long: `Run an operation from an approval request. The request must carry valid
+signatures from two different trusted operators and must not have expired.
+The executing operator must also provide a current MFA code.
+
+Keys are trusted only when imported with 'iamctl approve trust', including
+your own, and at most one of the two signing keys may be held on this
+machine. Each request runs once: its ID is recorded after execution and
+later attempts are refused.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, _ := cmd.Flags().GetString("profile")
+			mfaSerial, _ := cmd.Flags().GetString("mfa-serial")
+
+			// 1. Load the request and the trusted operator keys
+			req, err := approval.Load(args[0])
+			if err != nil {
+				return fmt.Errorf("❌ Execution refused: %v", err)
+			}
+
+			trusted, err := approval.TrustedKeys()
+			if err != nil {
+				return fmt.Errorf("❌ Execution refused: %v", err)
+			}
+			local, err := approval.LocalKeys()
+			if err != nil {
+				return fmt.Errorf("❌ Execution refused: %v", err)
+			}
+
+			// 2. Both signatures must be valid and the request unexpired
+			if err := req.Verify(trusted, local); err != nil {
+				return fmt.Errorf("❌ Execution refused: %v", err)
+			}
+
+			exec, ok := approval.Lookup(req.Operation)
+			if !ok {
+				return fmt.Errorf("❌ Execution refused: unknown operation %q", req.Operation)
+			}
+
+			fmt.Print(req.Summary())
+
+			// 3. Prove possession of a current MFA code before the API
+			// deadline starts
+			session, err := awssdk.RequireMFA(context.Background(), profile, mfaSerial, mfatoken.ForProfile(profile, prompt.MFACode))
+			if err != nil {
+				return handleAWSErrors(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			caller, err := awssdk.CallerIdentity(ctx, sts.NewFromConfig(session.Config))
+			if err != nil {
+				return handleAWSErrors(err)
+			}
+
+			// 4. Claim the request so it cannot run again
+			if err := approval.Claim(req, caller.String()); err != nil {
+				return fmt.Errorf("❌ Execution refused: %v", err)
+			}
+
+			// 5. Run the operation with the verified session
+			if err := exec(ctx, session.IAMClient(), req.Parameters); err != nil {
+				if releaseErr := approval.Release(req); releaseErr != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", releaseErr)
+				}
+				return fmt.Errorf("❌ Execution failed: %v", sanitizeError(err))
+			}
+
+			fmt.Printf("✅ %s executed\n", req.Operation)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().String("mfa-serial", "", "MFA device serial number (defaults to your only MFA device)")
+
+	return cmd
+}
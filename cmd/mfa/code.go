@@ -0,0 +1,64 @@
+package mfa
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/totp"
+)
+
+// NewCodeCommand creates the MFA code command
+func NewCodeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "code",
+		Short: "Generate the current MFA code from a sealed seed",
+		Long: `Print the current TOTP code for a virtual MFA seed, for break-glass
+automation users that have no phone. The seed file must be sealed to a local
+key (see 'mfa seed keygen' and 'mfa enable --recipient-key'); plaintext seeds
+are refused.
+
+Any command can use the seed directly by setting mfa_seed_file on the
+profile in ~/.aws/config.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			seedFile, _ := cmd.Flags().GetString("seed-file")
+			digits, _ := cmd.Flags().GetInt("digits")
+			period, _ := cmd.Flags().GetDuration("period")
+			algorithm, _ := cmd.Flags().GetString("algorithm")
+
+			if seedFile == "" {
+				return fmt.Errorf("--seed-file is required")
+			}
+
+			secret, err := mfatoken.LoadSealedSeed(seedFile)
+			if err != nil {
+				return fmt.Errorf("❌ Failed to read seed: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			code, err := mfatoken.CurrentCode(ctx, secret, totp.Config{
+				Algorithm: totp.Algorithm(algorithm),
+				Digits:    digits,
+				Period:    period,
+			})
+			if err != nil {
+				return fmt.Errorf("❌ Failed to generate code: %v", err)
+			}
+
+			// Only the code goes to stdout so the command can be an mfa_process
+			fmt.Println(code)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("seed-file", "", "Sealed seed file (required)")
+	cmd.Flags().Int("digits", 6, "Code length")
+	cmd.Flags().Duration("period", 30*time.Second, "Code period")
+	cmd.Flags().String("algorithm", string(totp.SHA1), "HMAC algorithm (SHA1, SHA256 or SHA512)")
+
+	return cmd
+}
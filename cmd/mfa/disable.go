@@ -1,15 +1,15 @@
 package mfa
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"os"
-	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/prompt"
+	"github.com/yourusername/iamctl/internal/approval"
 	"github.com/spf13/cobra"
 )
 
@@ -18,14 +18,19 @@ func NewDisableCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "disable",
 		Short: "Disable MFA for the current user",
-		Long:  `Disable MFA by deleting the VirtualMFADevice with double-confirmation.`,
+		Long: `Disable an MFA device with double-confirmation and a current MFA code.
+Virtual devices are also deleted. When the user has more than one device,
+choose it with --serial.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Get profile from flags or environment
 			profile, _ := cmd.Flags().GetString("profile")
+			serial, _ := cmd.Flags().GetString("serial")
+			mfaSerial, _ := cmd.Flags().GetString("mfa-serial")
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
+			// Look up the device under its own deadline; the prompts below
+			// wait on a human
+			lookupCtx, cancelLookup := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelLookup()
 
 			// Create IAM client
 			client, err := awssdk.NewIAMClient(profile)
@@ -34,46 +39,52 @@ func NewDisableCommand() *cobra.Command {
 			}
 
 			// Get current user
-			user, err := awssdk.GetCurrentUser(ctx, client)
+			user, err := awssdk.GetCurrentUser(lookupCtx, client)
 			if err != nil {
 				return handleMFAErrors(err)
 			}
 
-			// Get current password
-			password, err := getPassword()
+			// Pick the device to disable
+			device, err := selectDevice(lookupCtx, client, *user.UserName, serial)
 			if err != nil {
-				return fmt.Errorf("❌ Operation failed: Invalid credentials")
+				return fmt.Errorf("❌ Operation failed: %v", err)
 			}
 
-			// Clear password from memory when done
-			passwordBytes := []byte(password)
-			defer func() {
-				for i := range passwordBytes {
-					passwordBytes[i] = 0
+			// In approval mode, write a signed request instead of disabling
+			if approval.Requested(cmd) {
+				path, err := approval.CreateFromFlags(cmd, "mfa.disable", map[string]string{
+					"username": *user.UserName,
+					"serial":   device.SerialNumber,
+				})
+				if err != nil {
+					return fmt.Errorf("❌ Approval request failed: %v", err)
 				}
-			}()
-
-			// Get MFA token
-			mfaToken, err := getMFAToken()
-			if err != nil {
-				return fmt.Errorf("❌ Operation failed: Invalid credentials")
+				fmt.Printf("✅ Approval request written to %s\n", path)
+				return nil
 			}
 
 			// Double confirmation
-			fmt.Print("Are you sure you want to disable MFA? Type 'YES' to confirm: ")
-			reader := bufio.NewReader(os.Stdin)
-			confirmation, err := reader.ReadString('\n')
+			question := fmt.Sprintf("Are you sure you want to disable MFA device %s?", device.SerialNumber)
+			if err := prompt.Confirm(question); err != nil {
+				return fmt.Errorf("❌ Operation cancelled: %v", err)
+			}
+
+			// Prove possession of a current MFA code, by default from the
+			// device being disabled
+			if mfaSerial == "" && device.Type != DeviceTypeFIDO {
+				mfaSerial = device.SerialNumber
+			}
+			session, err := awssdk.RequireMFA(context.Background(), profile, mfaSerial, mfatoken.ForProfile(profile, prompt.MFACode))
 			if err != nil {
 				return fmt.Errorf("❌ Operation failed: Invalid credentials")
 			}
-			confirmation = strings.TrimSpace(confirmation)
 
-			if confirmation != "YES" {
-				return fmt.Errorf("❌ Operation cancelled")
-			}
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
 
-			// Disable MFA
-			err = disableMFA(ctx, client, profile, *user.UserName, password, mfaToken)
+			// Disable MFA with the verified session
+			err = disableMFA(ctx, session.IAMClient(), *user.UserName, device)
 			if err != nil {
 				return fmt.Errorf("❌ Operation failed: Invalid credentials")
 			}
@@ -85,49 +96,73 @@ func NewDisableCommand() *cobra.Command {
 
 	// Add flags
 	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().String("serial", "", "Serial number of the device to disable (required with more than one device)")
+	cmd.Flags().String("mfa-serial", "", "MFA device used to verify this operation (defaults to the device being disabled)")
+	approval.AddFlags(cmd)
 
 	return cmd
 }
 
-// disableMFA disables MFA for the user
-func disableMFA(ctx context.Context, client *iam.Client, profile string, username string, password, mfaToken string) error {
-	// First validate current credentials
-	if err := validateCredentials(ctx, client, profile, &username, password, mfaToken); err != nil {
-		return err
-	}
+func init() {
+	approval.Register("mfa.disable", executeApprovedDisable)
+}
 
-	// List MFA devices to get the serial number
-	listInput := &iam.ListMFADevicesInput{
-		UserName: &username,
+// executeApprovedDisable disables MFA for the user named in an approved request
+func executeApprovedDisable(ctx context.Context, client *iam.Client, params map[string]string) error {
+	device, err := selectDevice(ctx, client, params["username"], params["serial"])
+	if err != nil {
+		return err
 	}
+	return disableMFA(ctx, client, params["username"], device)
+}
 
-	listResult, err := client.ListMFADevices(ctx, listInput)
+// selectDevice returns the user's device with the given serial, or their
+// only device when serial is empty
+func selectDevice(ctx context.Context, client mfaAPI, username, serial string) (MFADevice, error) {
+	devices, err := listMFADevices(ctx, client, &username)
 	if err != nil {
-		return fmt.Errorf("failed to list MFA devices: %w", err)
+		return MFADevice{}, err
 	}
 
-	// Check if any MFA devices exist
-	if len(listResult.MFADevices) == 0 {
-		return fmt.Errorf("no MFA devices found for user")
+	if serial == "" {
+		switch len(devices) {
+		case 0:
+			return MFADevice{}, fmt.Errorf("no MFA devices found for user")
+		case 1:
+			return devices[0], nil
+		default:
+			return MFADevice{}, fmt.Errorf("user has %d MFA devices, choose one with --serial", len(devices))
+		}
 	}
 
-	// Use the first MFA device (assuming only one)
-	device := listResult.MFADevices[0]
+	for _, device := range devices {
+		if device.SerialNumber == serial {
+			return device, nil
+		}
+	}
+	return MFADevice{}, fmt.Errorf("MFA device %s not found for user", serial)
+}
 
+// disableMFA deactivates an MFA device and deletes it if it is virtual
+func disableMFA(ctx context.Context, client mfaAPI, username string, device MFADevice) error {
 	// Deactivate MFA device
 	deactivateInput := &iam.DeactivateMFADeviceInput{
 		UserName:     &username,
-		SerialNumber: device.SerialNumber,
+		SerialNumber: &device.SerialNumber,
 	}
 
-	_, err = client.DeactivateMFADevice(ctx, deactivateInput)
+	_, err := client.DeactivateMFADevice(ctx, deactivateInput)
 	if err != nil {
 		return fmt.Errorf("failed to deactivate MFA device: %w", err)
 	}
 
-	// Delete virtual MFA device
+	// Hardware and FIDO devices are only deactivated; virtual ones are deleted
+	if device.Type != DeviceTypeVirtual {
+		return nil
+	}
+
 	deleteInput := &iam.DeleteVirtualMFADeviceInput{
-		SerialNumber: device.SerialNumber,
+		SerialNumber: &device.SerialNumber,
 	}
 
 	_, err = client.DeleteVirtualMFADevice(ctx, deleteInput)
@@ -136,4 +171,4 @@ func disableMFA(ctx context.Context, client *iam.Client, profile string, usernam
 	}
 
 	return nil
-}
\ No newline at end of file
+}
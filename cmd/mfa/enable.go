@@ -3,29 +3,122 @@ package mfa
 import (
 	"context"
 	"fmt"
-	"net/url"
-	"syscall"
+	"io"
+	"os"
+	"regexp"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
-	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
+	"github.com/yourusername/iamctl/internal/audit"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/prompt"
+	"github.com/yourusername/iamctl/internal/seedbox"
+)
+
+// mfaAPI is the subset of the IAM client used to manage MFA devices
+type mfaAPI interface {
+	CreateVirtualMFADevice(context.Context, *iam.CreateVirtualMFADeviceInput, ...func(*iam.Options)) (*iam.CreateVirtualMFADeviceOutput, error)
+	EnableMFADevice(context.Context, *iam.EnableMFADeviceInput, ...func(*iam.Options)) (*iam.EnableMFADeviceOutput, error)
+	ListMFADevices(context.Context, *iam.ListMFADevicesInput, ...func(*iam.Options)) (*iam.ListMFADevicesOutput, error)
+	DeactivateMFADevice(context.Context, *iam.DeactivateMFADeviceInput, ...func(*iam.Options)) (*iam.DeactivateMFADeviceOutput, error)
+	DeleteVirtualMFADevice(context.Context, *iam.DeleteVirtualMFADeviceInput, ...func(*iam.Options)) (*iam.DeleteVirtualMFADeviceOutput, error)
+	ResyncMFADevice(context.Context, *iam.ResyncMFADeviceInput, ...func(*iam.Options)) (*iam.ResyncMFADeviceOutput, error)
+}
+
+// totpCodePattern matches a single six-digit TOTP code
+var totpCodePattern = regexp.MustCompile(`^[0-9]{6}$`)
+
+// deviceNamePattern matches the names IAM accepts for virtual MFA devices
+var deviceNamePattern = regexp.MustCompile(`^[\w+=,.@-]{1,226}$`)
+
+// Seed handoff formats for --seed-format
+const (
+	SeedFormatBase32 = "seed"
+	SeedFormatQR     = "qr"
 )
 
+// enrollOptions controls how a new virtual device is presented and verified
+type enrollOptions struct {
+	DeviceName string
+	QRFile     string
+	ShowSeed   bool
+	Out        io.Writer
+	ReadCodes  func() (string, string, error)
+
+	// Admin provisioning: the seed is written to SeedOut for the device
+	// owner, sealed to Recipient when set, and the device is tagged
+	SeedOut    string
+	SeedFormat string
+	Recipient  *seedbox.PublicKey
+	Tags       []types.Tag
+}
+
 // NewEnableCommand creates the MFA enable command
 func NewEnableCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "enable",
 		Short: "Enable MFA for the current user",
-		Long:  `Enable MFA by creating a VirtualMFADevice and generating a QR code for setup.`,
+		Long: `Enable MFA by creating a virtual MFA device, showing its QR code and
+activating it with two consecutive codes from your authenticator app.
+The new device is added alongside any existing ones (IAM allows up to 8).
+
+The QR code is drawn in the terminal by default. If it does not scan, write
+it to a PNG file with --qr-file or show the Base32 seed with --show-seed.
+
+Administrators can provision a device for another user with --username and
+--seed-out. The seed (or QR PNG with --seed-format qr) is written to a 0600
+file for the user, sealed to their public key when --recipient-key is given
+(see 'iamctl mfa seed keygen'). The device is activated once the user reads
+back two consecutive codes, and the provisioning is tagged on the device and
+recorded in the local audit log.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Get profile from flags or environment
 			profile, _ := cmd.Flags().GetString("profile")
+			qrFile, _ := cmd.Flags().GetString("qr-file")
+			showSeed, _ := cmd.Flags().GetBool("show-seed")
+			deviceName, _ := cmd.Flags().GetString("device-name")
+			username, _ := cmd.Flags().GetString("username")
+			seedOut, _ := cmd.Flags().GetString("seed-out")
+			seedFormat, _ := cmd.Flags().GetString("seed-format")
+			recipientKey, _ := cmd.Flags().GetString("recipient-key")
+
+			if qrFile != "" && showSeed {
+				return fmt.Errorf("--qr-file and --show-seed cannot be used together")
+			}
+			if deviceName != "" && !deviceNamePattern.MatchString(deviceName) {
+				return fmt.Errorf("invalid device name %q", deviceName)
+			}
+			if username != "" && seedOut == "" {
+				return fmt.Errorf("--seed-out is required with --username so the seed is handed off, not shown to you")
+			}
+			if seedOut != "" && (qrFile != "" || showSeed) {
+				return fmt.Errorf("--seed-out cannot be combined with --qr-file or --show-seed")
+			}
+			if recipientKey != "" && seedOut == "" {
+				return fmt.Errorf("--recipient-key requires --seed-out")
+			}
+			if seedFormat != SeedFormatBase32 && seedFormat != SeedFormatQR {
+				return fmt.Errorf("invalid --seed-format %q (use seed or qr)", seedFormat)
+			}
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			var recipient *seedbox.PublicKey
+			if recipientKey != "" {
+				public, err := seedbox.ReadPublicKey(recipientKey)
+				if err != nil {
+					return fmt.Errorf("❌ MFA enrollment failed: %v", err)
+				}
+				recipient = &public
+			}
+
+			// Enrollment waits on the user, and a handoff on a second person
+			timeout := 5 * time.Minute
+			if username != "" {
+				timeout = 15 * time.Minute
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
 			defer cancel()
 
 			// Create IAM client
@@ -40,131 +133,249 @@ func NewEnableCommand() *cobra.Command {
 				return handleMFAErrors(err)
 			}
 
-			// Get current password
-			password, err := getPassword()
-			if err != nil {
-				return fmt.Errorf("❌ Operation failed: Invalid credentials")
+			target := user.UserName
+			opts := enrollOptions{
+				QRFile:     qrFile,
+				ShowSeed:   showSeed,
+				Out:        os.Stdout,
+				ReadCodes:  getConsecutiveCodes,
+				SeedOut:    seedOut,
+				SeedFormat: seedFormat,
+				Recipient:  recipient,
 			}
-
-			// Clear password from memory when done
-			passwordBytes := []byte(password)
-			defer func() {
-				for i := range passwordBytes {
-					passwordBytes[i] = 0
+			if username != "" {
+				target = aws.String(username)
+				opts.Tags = []types.Tag{
+					{Key: aws.String("iamctl:provisioned-by"), Value: user.Arn},
+					{Key: aws.String("iamctl:provisioned-for"), Value: target},
 				}
-			}()
+			}
 
-			// Get MFA token
-			mfaToken, err := getMFAToken()
-			if err != nil {
-				return fmt.Errorf("❌ Operation failed: Invalid credentials")
+			// Virtual device names are unique per account, so default to a timestamped name
+			if deviceName == "" {
+				deviceName = fmt.Sprintf("iamctl-%s-%d", *target, time.Now().Unix())
 			}
+			opts.DeviceName = deviceName
 
-			// Enable MFA
-			qrCodeURI, err := enableMFA(ctx, client, profile, user.UserName, password, mfaToken)
+			// Enroll and activate the device
+			serial, err := enableMFA(ctx, client, target, opts)
 			if err != nil {
-				return fmt.Errorf("❌ Operation failed: Invalid credentials")
+				return fmt.Errorf("❌ MFA enrollment failed: %v", err)
 			}
 
-			fmt.Printf("✅ MFA enabled. Scan: %s\n", qrCodeURI)
+			if username != "" {
+				if err := recordProvisioning(aws.ToString(user.Arn), username, serial, opts); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: Failed to write audit record: %v\n", err)
+				}
+				fmt.Printf("✅ MFA enabled for %s. Device: %s\n", username, serial)
+				return nil
+			}
+
+			fmt.Printf("✅ MFA enabled. Device: %s\n", serial)
 			return nil
 		},
 	}
 
 	// Add flags
 	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().String("qr-file", "", "Write the QR code to this PNG file instead of the terminal")
+	cmd.Flags().Bool("show-seed", false, "Show the Base32 seed instead of a QR code")
+	cmd.Flags().String("device-name", "", "Name for the new virtual device (defaults to iamctl-<user>-<timestamp>)")
+	cmd.Flags().String("username", "", "Provision a device for this user instead of yourself (requires --seed-out)")
+	cmd.Flags().String("seed-out", "", "Write the seed for the device owner to this new 0600 file")
+	cmd.Flags().String("seed-format", SeedFormatBase32, "What --seed-out contains: seed (Base32) or qr (PNG)")
+	cmd.Flags().String("recipient-key", "", "Seal --seed-out to the owner's public key file")
 
 	return cmd
 }
 
-// enableMFA enables MFA for the user
-func enableMFA(ctx context.Context, client *iam.Client, profile string, username *string, password, mfaToken string) (string, error) {
-	// First validate current credentials
-	if err := validateCredentials(ctx, client, profile, username, password, mfaToken); err != nil {
+// enableMFA creates a virtual MFA device, presents it for enrollment and
+// activates it with two consecutive codes. The device is deleted again if
+// any step after its creation fails.
+func enableMFA(ctx context.Context, client mfaAPI, username *string, opts enrollOptions) (serial string, err error) {
+	// 1. Make sure the user has room for another device
+	devices, err := listMFADevices(ctx, client, username)
+	if err != nil {
 		return "", err
 	}
-
-	// Create virtual MFA device
-	deviceName := fmt.Sprintf("iamctl-%s-%d", *username, time.Now().Unix())
-	createDeviceInput := &iam.CreateVirtualMFADeviceInput{
-		VirtualMFADeviceName: aws.String(deviceName),
+	if len(devices) >= maxMFADevices {
+		return "", fmt.Errorf("user already has the maximum of %d MFA devices", maxMFADevices)
 	}
 
-	deviceResult, err := client.CreateVirtualMFADevice(ctx, createDeviceInput)
+	// 2. Create the virtual device
+	deviceResult, err := client.CreateVirtualMFADevice(ctx, &iam.CreateVirtualMFADeviceInput{
+		VirtualMFADeviceName: aws.String(opts.DeviceName),
+		Tags:                 opts.Tags,
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to create virtual MFA device: %w", err)
 	}
+	device := deviceResult.VirtualMFADevice
 
-	// Enable the MFA device
-	enableInput := &iam.EnableMFADeviceInput{
-		UserName:            username,
-		SerialNumber:        deviceResult.VirtualMFADevice.SerialNumber,
-		AuthenticationCode1: aws.String(mfaToken[:6]), // First 6 digits
-		AuthenticationCode2: aws.String(mfaToken[6:]), // Next 6 digits
+	presented := false
+	defer func() {
+		if err != nil {
+			cleanupVirtualDevice(ctx, client, device.SerialNumber)
+			// The seed of a deleted device is useless, don't leave it around
+			if presented && opts.SeedOut != "" {
+				os.Remove(opts.SeedOut)
+			}
+		}
+	}()
+
+	// 3. Present the device to the user's authenticator
+	if err = presentDevice(opts, device.QRCodePNG, device.Base32StringSeed); err != nil {
+		return "", err
 	}
+	presented = true
 
-	_, err = client.EnableMFADevice(ctx, enableInput)
+	// 4. Activate it with two consecutive codes
+	code1, code2, err := opts.ReadCodes()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = client.EnableMFADevice(ctx, &iam.EnableMFADeviceInput{
+		UserName:            username,
+		SerialNumber:        device.SerialNumber,
+		AuthenticationCode1: aws.String(code1),
+		AuthenticationCode2: aws.String(code2),
+	})
 	if err != nil {
-		// Clean up the virtual MFA device if enabling failed
-		_, deleteErr := client.DeleteVirtualMFADevice(ctx, &iam.DeleteVirtualMFADeviceInput{
-			SerialNumber: deviceResult.VirtualMFADevice.SerialNumber,
-		})
-		if deleteErr != nil {
-			// Log but don't return this error as we're already handling another error
-			fmt.Printf("Warning: Failed to clean up virtual MFA device: %v\n", deleteErr)
-		}
 		return "", fmt.Errorf("failed to enable MFA device: %w", err)
 	}
 
-	// Return the QR code URI (convert byte array to string)
-	// Using the QRCodePNG from AWS API response which is the proper way
-	return string(deviceResult.VirtualMFADevice.QRCodePNG), nil
+	return aws.ToString(device.SerialNumber), nil
 }
 
-// generateQRCode generates a TOTP QR code URI following AWS best practices
-// This function demonstrates the proper pattern but we use the AWS-generated QRCodePNG in practice
-func generateQRCode(ctx context.Context, username, serial *string) (string, error) {
-	// Proper encoding per security best practices
-	encodedUsername := url.PathEscape(*username)
-	// Note: NO direct secret exposure - uses AWS serial only
-	uri := fmt.Sprintf("otpauth://totp/AWS:%s?secret=%s&issuer=AWS",
-		encodedUsername,
-		url.QueryEscape(*serial))
-
-	return uri, nil
+// presentDevice shows the new device as a terminal QR code, a PNG file or
+// its Base32 seed
+func presentDevice(opts enrollOptions, qrPNG, seed []byte) error {
+	switch {
+	case opts.SeedOut != "":
+		return handOffSeed(opts, qrPNG, seed)
+	case opts.ShowSeed:
+		fmt.Fprintf(opts.Out, "Add this seed to your authenticator app: %s\n", seed)
+	case opts.QRFile != "":
+		if err := os.WriteFile(opts.QRFile, qrPNG, 0600); err != nil {
+			return fmt.Errorf("failed to write QR code: %w", err)
+		}
+		fmt.Fprintf(opts.Out, "QR code written to %s. Scan it with your authenticator app.\n", opts.QRFile)
+	default:
+		modules, err := decodeQRModules(qrPNG)
+		if err != nil {
+			return fmt.Errorf("%w (use --qr-file or --show-seed instead)", err)
+		}
+		fmt.Fprintln(opts.Out, "Scan this QR code with your authenticator app:")
+		renderQRModules(opts.Out, modules)
+	}
+	return nil
 }
 
-// validateCredentials validates the user's current password and MFA token
-func validateCredentials(ctx context.Context, client *iam.Client, profile string, username *string, password, mfaToken string) error {
-	// In a real implementation, you would validate the current password and MFA token
-	// For now, we'll just return nil to indicate success
+// handOffSeed writes the seed or QR code to a new owner-only file for the
+// device owner, sealed to their public key when one was given
+func handOffSeed(opts enrollOptions, qrPNG, seed []byte) error {
+	contentType, payload := seedbox.ContentSeed, append(append([]byte{}, seed...), '\n')
+	if opts.SeedFormat == SeedFormatQR {
+		contentType, payload = seedbox.ContentQR, qrPNG
+	}
+
+	if opts.Recipient != nil {
+		sealed, err := seedbox.Seal(*opts.Recipient, contentType, payload)
+		if err != nil {
+			return fmt.Errorf("failed to seal seed: %w", err)
+		}
+		payload = sealed
+	}
+
+	// Never overwrite an existing file or follow a planted symlink
+	f, err := os.OpenFile(opts.SeedOut, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create seed file: %w", err)
+	}
+	if _, err := f.Write(payload); err != nil {
+		f.Close()
+		os.Remove(opts.SeedOut)
+		return fmt.Errorf("failed to write seed file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(opts.SeedOut)
+		return fmt.Errorf("failed to write seed file: %w", err)
+	}
+
+	if opts.Recipient != nil {
+		fmt.Fprintf(opts.Out, "Seed written to %s, sealed to %s. They can open it with 'iamctl mfa seed open %s'.\n",
+			opts.SeedOut, opts.Recipient.Name, opts.SeedOut)
+	} else {
+		fmt.Fprintf(opts.Out, "Seed written to %s. Deliver it to the device owner over a secure channel.\n", opts.SeedOut)
+	}
+	fmt.Fprintln(opts.Out, "Ask them to add it to their authenticator and read you two consecutive codes.")
 	return nil
 }
 
-// getPassword securely reads a password from the terminal
-func getPassword() (string, error) {
-	fmt.Print("Enter current password: ")
-	password, err := term.ReadPassword(int(syscall.Stdin))
-	fmt.Println() // Add a newline after password input
+// recordProvisioning notes in the audit log that an administrator set up a
+// device for another user
+func recordProvisioning(admin, username, serial string, opts enrollOptions) error {
+	details := map[string]string{
+		"serial":      serial,
+		"seed_out":    opts.SeedOut,
+		"seed_format": opts.SeedFormat,
+	}
+	if opts.Recipient != nil {
+		details["sealed_to"] = opts.Recipient.Name
+	}
+
+	return audit.Record(audit.Event{
+		Actor:   admin,
+		Action:  "mfa.provision",
+		Target:  username,
+		Details: details,
+	})
+}
+
+// cleanupVirtualDevice deletes a virtual device left behind by a failed
+// enrollment. It uses its own deadline since ctx may already have expired.
+func cleanupVirtualDevice(ctx context.Context, client mfaAPI, serial *string) {
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 15*time.Second)
+	defer cancel()
+
+	_, err := client.DeleteVirtualMFADevice(cleanupCtx, &iam.DeleteVirtualMFADeviceInput{
+		SerialNumber: serial,
+	})
 	if err != nil {
-		return "", err
+		// Log but don't return this error as we're already handling another error
+		fmt.Fprintf(os.Stderr, "Warning: Failed to clean up virtual MFA device: %v\n", err)
 	}
-	return string(password), nil
 }
 
-// getMFAToken securely reads an MFA token from the terminal
-func getMFAToken() (string, error) {
-	fmt.Print("Enter current MFA token: ")
-	token, err := term.ReadPassword(int(syscall.Stdin))
-	fmt.Println() // Add a newline after token input
+// getConsecutiveCodes reads two consecutive codes from a newly added device
+func getConsecutiveCodes() (string, string, error) {
+	code1, err := prompt.Code("Enter the current code from the authenticator")
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-	return string(token), nil
+
+	code2, err := prompt.Code("Wait for the code to change, then enter the next code")
+	if err != nil {
+		return "", "", err
+	}
+
+	return checkConsecutiveCodes(code1, code2)
+}
+
+// checkConsecutiveCodes validates the format of two consecutive codes
+func checkConsecutiveCodes(code1, code2 string) (string, string, error) {
+	if !totpCodePattern.MatchString(code1) || !totpCodePattern.MatchString(code2) {
+		return "", "", fmt.Errorf("codes must be six digits")
+	}
+	if code1 == code2 {
+		return "", "", fmt.Errorf("the two codes must be consecutive, not the same code twice")
+	}
+	return code1, code2, nil
 }
 
 // handleMFAErrors converts SDK errors to user-friendly messages with unified error messaging
 func handleMFAErrors(err error) error {
 	// Always return generic error message for security
 	return fmt.Errorf("❌ Operation failed: Invalid credentials")
-}
\ No newline at end of file
+}
@@ -1,15 +1,29 @@
 package mfa
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/smithy-go"
 	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/exitcode"
+	"github.com/yourusername/iamctl/internal/seedbox"
 )
 
 // Mock IAM client for testing
@@ -19,9 +33,10 @@ type mockIAMClient struct {
 	listMFADevicesFunc         func(context.Context, *iam.ListMFADevicesInput) (*iam.ListMFADevicesOutput, error)
 	deactivateMFADeviceFunc    func(context.Context, *iam.DeactivateMFADeviceInput) (*iam.DeactivateMFADeviceOutput, error)
 	deleteVirtualMFADeviceFunc func(context.Context, *iam.DeleteVirtualMFADeviceInput) (*iam.DeleteVirtualMFADeviceOutput, error)
+	resyncMFADeviceFunc        func(context.Context, *iam.ResyncMFADeviceInput) (*iam.ResyncMFADeviceOutput, error)
 }
 
-func (m *mockIAMClient) CreateVirtualMFADevice(ctx context.Context, input *iam.CreateVirtualMFADeviceInput) (*iam.CreateVirtualMFADeviceOutput, error) {
+func (m *mockIAMClient) CreateVirtualMFADevice(ctx context.Context, input *iam.CreateVirtualMFADeviceInput, optFns ...func(*iam.Options)) (*iam.CreateVirtualMFADeviceOutput, error) {
 	if m.createVirtualMFADeviceFunc != nil {
 		return m.createVirtualMFADeviceFunc(ctx, input)
 	}
@@ -33,14 +48,14 @@ func (m *mockIAMClient) CreateVirtualMFADevice(ctx context.Context, input *iam.C
 	}, nil
 }
 
-func (m *mockIAMClient) EnableMFADevice(ctx context.Context, input *iam.EnableMFADeviceInput) (*iam.EnableMFADeviceOutput, error) {
+func (m *mockIAMClient) EnableMFADevice(ctx context.Context, input *iam.EnableMFADeviceInput, optFns ...func(*iam.Options)) (*iam.EnableMFADeviceOutput, error) {
 	if m.enableMFADeviceFunc != nil {
 		return m.enableMFADeviceFunc(ctx, input)
 	}
 	return &iam.EnableMFADeviceOutput{}, nil
 }
 
-func (m *mockIAMClient) ListMFADevices(ctx context.Context, input *iam.ListMFADevicesInput) (*iam.ListMFADevicesOutput, error) {
+func (m *mockIAMClient) ListMFADevices(ctx context.Context, input *iam.ListMFADevicesInput, optFns ...func(*iam.Options)) (*iam.ListMFADevicesOutput, error) {
 	if m.listMFADevicesFunc != nil {
 		return m.listMFADevicesFunc(ctx, input)
 	}
@@ -54,70 +69,194 @@ func (m *mockIAMClient) ListMFADevices(ctx context.Context, input *iam.ListMFADe
 	}, nil
 }
 
-func (m *mockIAMClient) DeactivateMFADevice(ctx context.Context, input *iam.DeactivateMFADeviceInput) (*iam.DeactivateMFADeviceOutput, error) {
+func (m *mockIAMClient) DeactivateMFADevice(ctx context.Context, input *iam.DeactivateMFADeviceInput, optFns ...func(*iam.Options)) (*iam.DeactivateMFADeviceOutput, error) {
 	if m.deactivateMFADeviceFunc != nil {
 		return m.deactivateMFADeviceFunc(ctx, input)
 	}
 	return &iam.DeactivateMFADeviceOutput{}, nil
 }
 
-func (m *mockIAMClient) DeleteVirtualMFADevice(ctx context.Context, input *iam.DeleteVirtualMFADeviceInput) (*iam.DeleteVirtualMFADeviceOutput, error) {
+func (m *mockIAMClient) DeleteVirtualMFADevice(ctx context.Context, input *iam.DeleteVirtualMFADeviceInput, optFns ...func(*iam.Options)) (*iam.DeleteVirtualMFADeviceOutput, error) {
 	if m.deleteVirtualMFADeviceFunc != nil {
 		return m.deleteVirtualMFADeviceFunc(ctx, input)
 	}
 	return &iam.DeleteVirtualMFADeviceOutput{}, nil
 }
 
+func (m *mockIAMClient) ResyncMFADevice(ctx context.Context, input *iam.ResyncMFADeviceInput, optFns ...func(*iam.Options)) (*iam.ResyncMFADeviceOutput, error) {
+	if m.resyncMFADeviceFunc != nil {
+		return m.resyncMFADeviceFunc(ctx, input)
+	}
+	return &iam.ResyncMFADeviceOutput{}, nil
+}
+
 func TestDeviceRegistration(t *testing.T) {
 	// Setup mock client
+	var enableInput *iam.EnableMFADeviceInput
 	client := &mockIAMClient{
 		createVirtualMFADeviceFunc: func(ctx context.Context, input *iam.CreateVirtualMFADeviceInput) (*iam.CreateVirtualMFADeviceOutput, error) {
 			return &iam.CreateVirtualMFADeviceOutput{
 				VirtualMFADevice: &types.VirtualMFADevice{
-					SerialNumber: aws.String("arn:aws:iam::123456789012:mfa/testuser"),
-					QRCodePNG:    []byte("otpauth://totp/AWS:testuser?secret=TESTSECRET"),
+					SerialNumber:     aws.String("arn:aws:iam::123456789012:mfa/testuser"),
+					Base32StringSeed: []byte("JBSWY3DPEHPK3PXP"),
 				},
 			}, nil
 		},
 		enableMFADeviceFunc: func(ctx context.Context, input *iam.EnableMFADeviceInput) (*iam.EnableMFADeviceOutput, error) {
+			enableInput = input
 			return &iam.EnableMFADeviceOutput{}, nil
 		},
 	}
 
 	// Test successful device registration
+	var out bytes.Buffer
 	ctx := context.Background()
-	qrCodeURI, err := enableMFAWithClient(ctx, client, "test-profile", aws.String("testuser"), "password", "123456789012")
+	serial, err := enableMFA(ctx, client, aws.String("testuser"), enrollOptions{
+		DeviceName: "test-device",
+		ShowSeed:   true,
+		Out:        &out,
+		ReadCodes: func() (string, string, error) {
+			return "123456", "654321", nil
+		},
+	})
 	if err != nil {
-		t.Errorf("Expected successful device registration, got error: %v", err)
+		t.Fatalf("Expected successful device registration, got error: %v", err)
 	}
 
-	if qrCodeURI == "" {
-		t.Error("Expected QR code URI, got empty string")
+	if serial != "arn:aws:iam::123456789012:mfa/testuser" {
+		t.Errorf("Expected device serial, got %q", serial)
+	}
+	if !strings.Contains(out.String(), "JBSWY3DPEHPK3PXP") {
+		t.Error("Expected seed to be shown")
+	}
+	if *enableInput.AuthenticationCode1 != "123456" || *enableInput.AuthenticationCode2 != "654321" {
+		t.Error("Expected both codes to be passed to EnableMFADevice")
 	}
 }
 
 func TestInvalidToken(t *testing.T) {
 	// Setup mock client that returns MFA error
+	deleted := false
 	client := &mockIAMClient{
-		createVirtualMFADeviceFunc: func(ctx context.Context, input *iam.CreateVirtualMFADeviceInput) (*iam.CreateVirtualMFADeviceOutput, error) {
-			return &iam.CreateVirtualMFADeviceOutput{
-				VirtualMFADevice: &types.VirtualMFADevice{
-					SerialNumber: aws.String("arn:aws:iam::123456789012:mfa/testuser"),
-					QRCodePNG:    []byte("otpauth://totp/AWS:testuser?secret=TESTSECRET"),
-				},
-			}, nil
-		},
 		enableMFADeviceFunc: func(ctx context.Context, input *iam.EnableMFADeviceInput) (*iam.EnableMFADeviceOutput, error) {
 			return nil, &awssdk.PermissionError{Err: errors.New("access denied")}
 		},
+		deleteVirtualMFADeviceFunc: func(ctx context.Context, input *iam.DeleteVirtualMFADeviceInput) (*iam.DeleteVirtualMFADeviceOutput, error) {
+			deleted = true
+			return &iam.DeleteVirtualMFADeviceOutput{}, nil
+		},
 	}
 
 	// Test device registration failure due to invalid token
 	ctx := context.Background()
-	_, err := enableMFAWithClient(ctx, client, "test-profile", aws.String("testuser"), "password", "invalid")
+	_, err := enableMFA(ctx, client, aws.String("testuser"), enrollOptions{
+		DeviceName: "test-device",
+		ShowSeed:   true,
+		Out:        io.Discard,
+		ReadCodes: func() (string, string, error) {
+			return "000000", "111111", nil
+		},
+	})
 	if err == nil {
 		t.Error("Expected device registration to fail due to invalid token")
 	}
+	if !deleted {
+		t.Error("Expected the virtual device to be cleaned up")
+	}
+}
+
+func TestEnrollmentCleanupOnPresentationFailure(t *testing.T) {
+	// The mock QR code is not a PNG, so terminal rendering fails
+	deleted := false
+	client := &mockIAMClient{
+		deleteVirtualMFADeviceFunc: func(ctx context.Context, input *iam.DeleteVirtualMFADeviceInput) (*iam.DeleteVirtualMFADeviceOutput, error) {
+			deleted = true
+			return &iam.DeleteVirtualMFADeviceOutput{}, nil
+		},
+	}
+
+	_, err := enableMFA(context.Background(), client, aws.String("testuser"), enrollOptions{
+		DeviceName: "test-device",
+		Out:        io.Discard,
+		ReadCodes: func() (string, string, error) {
+			t.Fatal("codes should not be requested")
+			return "", "", nil
+		},
+	})
+	if err == nil {
+		t.Error("Expected enrollment to fail")
+	}
+	if !deleted {
+		t.Error("Expected the virtual device to be cleaned up")
+	}
+}
+
+func TestCheckConsecutiveCodes(t *testing.T) {
+	if _, _, err := checkConsecutiveCodes("123456", "234567"); err != nil {
+		t.Errorf("Expected valid codes to pass, got %v", err)
+	}
+	if _, _, err := checkConsecutiveCodes("123456", "123456"); err == nil {
+		t.Error("Expected repeated code to fail")
+	}
+	if _, _, err := checkConsecutiveCodes("12345", "234567"); err == nil {
+		t.Error("Expected short code to fail")
+	}
+}
+
+func TestDecodeQRModules(t *testing.T) {
+	// Build a 21x21 grid with the three finder patterns and a diagonal
+	const size = 21
+	modules := make([][]bool, size)
+	for row := range modules {
+		modules[row] = make([]bool, size)
+	}
+	finder := func(top, left int) {
+		for r := 0; r < 7; r++ {
+			for c := 0; c < 7; c++ {
+				ring := r == 0 || r == 6 || c == 0 || c == 6
+				center := r >= 2 && r <= 4 && c >= 2 && c <= 4
+				modules[top+r][left+c] = ring || center
+			}
+		}
+	}
+	finder(0, 0)
+	finder(0, size-7)
+	finder(size-7, 0)
+	for i := 8; i < size; i++ {
+		modules[i][i] = true
+	}
+
+	// Render it the way AWS does: 4px modules with a quiet zone
+	const scale, quiet = 4, 4
+	dim := (size + 2*quiet) * scale
+	img := image.NewGray(image.Rect(0, 0, dim, dim))
+	for y := 0; y < dim; y++ {
+		for x := 0; x < dim; x++ {
+			row, col := y/scale-quiet, x/scale-quiet
+			img.SetGray(x, y, color.Gray{Y: 255})
+			if row >= 0 && col >= 0 && row < size && col < size && modules[row][col] {
+				img.SetGray(x, y, color.Gray{Y: 0})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := decodeQRModules(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Expected QR code to decode, got %v", err)
+	}
+	if !reflect.DeepEqual(decoded, modules) {
+		t.Error("Decoded modules do not match the source grid")
+	}
+
+	var out bytes.Buffer
+	renderQRModules(&out, decoded)
+	if lines := strings.Count(out.String(), "\n"); lines != (size+2*qrQuietZone+1)/2 {
+		t.Errorf("Expected %d rendered lines, got %d", (size+2*qrQuietZone+1)/2, lines)
+	}
 }
 
 func TestRotation(t *testing.T) {
@@ -126,7 +265,7 @@ func TestRotation(t *testing.T) {
 
 	// Test MFA status with old device
 	ctx := context.Background()
-	status, err := getMFAStatusWithClient(ctx, client, aws.String("testuser"))
+	status, err := getMFAStatus(ctx, client, aws.String("testuser"))
 	if err != nil {
 		t.Errorf("Expected successful status check, got error: %v", err)
 	}
@@ -135,75 +274,450 @@ func TestRotation(t *testing.T) {
 		t.Error("Expected MFA to be enabled")
 	}
 
+	if len(status.Devices) != 1 || status.Devices[0].AgeDays() < 90 {
+		t.Errorf("Expected one device older than 90 days, got %+v", status.Devices)
+	}
+
 	// Device is 100 days old, so rotation should be recommended
 	// This is checked in the status command output, not in the status struct
 }
 
-// enableMFAWithClient is a testable version of enableMFA that accepts a mock client
-func enableMFAWithClient(ctx context.Context, client *mockIAMClient, profile string, username *string, password, mfaToken string) (string, error) {
-	// Create virtual MFA device
-	deviceName := "test-device"
-	createDeviceInput := &iam.CreateVirtualMFADeviceInput{
-		VirtualMFADeviceName: aws.String(deviceName),
+func TestDeviceType(t *testing.T) {
+	tests := map[string]string{
+		"arn:aws:iam::123456789012:mfa/phone":          DeviceTypeVirtual,
+		"arn:aws:iam::123456789012:u2f/user/alice/key": DeviceTypeFIDO,
+		"GAHT12345678": DeviceTypeHardware,
 	}
+	for serial, expected := range tests {
+		if actual := deviceType(serial); actual != expected {
+			t.Errorf("deviceType(%q) = %q, expected %q", serial, actual, expected)
+		}
+	}
+}
 
-	deviceResult, err := client.CreateVirtualMFADevice(ctx, createDeviceInput)
-	if err != nil {
-		return "", err
+func TestDisableRequiresSerialWithMultipleDevices(t *testing.T) {
+	client := &mockIAMClient{
+		listMFADevicesFunc: func(ctx context.Context, input *iam.ListMFADevicesInput) (*iam.ListMFADevicesOutput, error) {
+			return &iam.ListMFADevicesOutput{
+				MFADevices: []types.MFADevice{
+					{SerialNumber: aws.String("arn:aws:iam::123456789012:mfa/phone"), EnableDate: aws.Time(time.Now())},
+					{SerialNumber: aws.String("GAHT12345678"), EnableDate: aws.Time(time.Now())},
+				},
+			}, nil
+		},
 	}
+	ctx := context.Background()
 
-	// Enable the MFA device
-	enableInput := &iam.EnableMFADeviceInput{
-		UserName:            username,
-		SerialNumber:        deviceResult.VirtualMFADevice.SerialNumber,
-		AuthenticationCode1: aws.String(mfaToken[:6]),
-		AuthenticationCode2: aws.String(mfaToken[6:]),
+	if _, err := selectDevice(ctx, client, "testuser", ""); err == nil {
+		t.Error("Expected an error when no serial is given for multiple devices")
 	}
 
-	_, err = client.EnableMFADevice(ctx, enableInput)
+	device, err := selectDevice(ctx, client, "testuser", "GAHT12345678")
 	if err != nil {
-		// Clean up the virtual MFA device if enabling failed
-		_, deleteErr := client.DeleteVirtualMFADevice(ctx, &iam.DeleteVirtualMFADeviceInput{
-			SerialNumber: deviceResult.VirtualMFADevice.SerialNumber,
+		t.Fatalf("Expected device to be found, got %v", err)
+	}
+
+	// Hardware tokens are deactivated but never deleted as virtual devices
+	client.deleteVirtualMFADeviceFunc = func(ctx context.Context, input *iam.DeleteVirtualMFADeviceInput) (*iam.DeleteVirtualMFADeviceOutput, error) {
+		t.Error("DeleteVirtualMFADevice should not be called for a hardware token")
+		return nil, nil
+	}
+	if err := disableMFA(ctx, client, "testuser", device); err != nil {
+		t.Errorf("Expected disable to succeed, got %v", err)
+	}
+}
+
+func TestEnableRejectsNinthDevice(t *testing.T) {
+	client := &mockIAMClient{
+		listMFADevicesFunc: func(ctx context.Context, input *iam.ListMFADevicesInput) (*iam.ListMFADevicesOutput, error) {
+			return &iam.ListMFADevicesOutput{MFADevices: make([]types.MFADevice, maxMFADevices)}, nil
+		},
+		createVirtualMFADeviceFunc: func(ctx context.Context, input *iam.CreateVirtualMFADeviceInput) (*iam.CreateVirtualMFADeviceOutput, error) {
+			t.Error("CreateVirtualMFADevice should not be called")
+			return nil, errors.New("unexpected call")
+		},
+	}
+
+	_, err := enableMFA(context.Background(), client, aws.String("testuser"), enrollOptions{DeviceName: "ninth", Out: io.Discard})
+	if err == nil {
+		t.Error("Expected enable to fail when the device limit is reached")
+	}
+}
+
+func TestRotateMFA(t *testing.T) {
+	oldDevice := MFADevice{SerialNumber: "arn:aws:iam::123456789012:mfa/old", Type: DeviceTypeVirtual}
+	readCodes := func() (string, string, error) {
+		return "123456", "654321", nil
+	}
+
+	t.Run("old device removed after new device verifies", func(t *testing.T) {
+		var calls []string
+		verify := func(ctx context.Context, newSerial string) (mfaAPI, error) {
+			calls = append(calls, "verify "+newSerial)
+			return &mockIAMClient{
+				deactivateMFADeviceFunc: func(ctx context.Context, input *iam.DeactivateMFADeviceInput) (*iam.DeactivateMFADeviceOutput, error) {
+					calls = append(calls, "deactivate "+*input.SerialNumber)
+					return &iam.DeactivateMFADeviceOutput{}, nil
+				},
+				deleteVirtualMFADeviceFunc: func(ctx context.Context, input *iam.DeleteVirtualMFADeviceInput) (*iam.DeleteVirtualMFADeviceOutput, error) {
+					calls = append(calls, "delete "+*input.SerialNumber)
+					return &iam.DeleteVirtualMFADeviceOutput{}, nil
+				},
+			}, nil
+		}
+		client := &mockIAMClient{
+			createVirtualMFADeviceFunc: func(ctx context.Context, input *iam.CreateVirtualMFADeviceInput) (*iam.CreateVirtualMFADeviceOutput, error) {
+				calls = append(calls, "create")
+				return &iam.CreateVirtualMFADeviceOutput{
+					VirtualMFADevice: &types.VirtualMFADevice{SerialNumber: aws.String("arn:aws:iam::123456789012:mfa/new")},
+				}, nil
+			},
+			enableMFADeviceFunc: func(ctx context.Context, input *iam.EnableMFADeviceInput) (*iam.EnableMFADeviceOutput, error) {
+				calls = append(calls, "enable")
+				return &iam.EnableMFADeviceOutput{}, nil
+			},
+			deactivateMFADeviceFunc: func(ctx context.Context, input *iam.DeactivateMFADeviceInput) (*iam.DeactivateMFADeviceOutput, error) {
+				t.Error("Old device must be deactivated with the MFA-verified client")
+				return nil, nil
+			},
+		}
+
+		newSerial, err := rotateMFA(context.Background(), client, "testuser", oldDevice, enrollOptions{
+			DeviceName: "new", ShowSeed: true, Out: io.Discard, ReadCodes: readCodes,
+		}, verify)
+		if err != nil {
+			t.Fatalf("Expected rotation to succeed, got %v", err)
+		}
+		if newSerial != "arn:aws:iam::123456789012:mfa/new" {
+			t.Errorf("Unexpected new serial %q", newSerial)
+		}
+
+		expected := []string{"create", "enable", "verify arn:aws:iam::123456789012:mfa/new", "deactivate " + oldDevice.SerialNumber, "delete " + oldDevice.SerialNumber}
+		if !reflect.DeepEqual(calls, expected) {
+			t.Errorf("Expected calls %v, got %v", expected, calls)
+		}
+	})
+
+	t.Run("old device kept when new device fails", func(t *testing.T) {
+		client := &mockIAMClient{
+			enableMFADeviceFunc: func(ctx context.Context, input *iam.EnableMFADeviceInput) (*iam.EnableMFADeviceOutput, error) {
+				return nil, errors.New("InvalidAuthenticationCode")
+			},
+			deactivateMFADeviceFunc: func(ctx context.Context, input *iam.DeactivateMFADeviceInput) (*iam.DeactivateMFADeviceOutput, error) {
+				t.Error("Old device must not be deactivated")
+				return nil, nil
+			},
+		}
+
+		newSerial, err := rotateMFA(context.Background(), client, "testuser", oldDevice, enrollOptions{
+			DeviceName: "new", ShowSeed: true, Out: io.Discard, ReadCodes: readCodes,
+		}, func(ctx context.Context, newSerial string) (mfaAPI, error) {
+			t.Error("Verification must not be attempted without a new device")
+			return nil, errors.New("unexpected call")
+		})
+		if err == nil || newSerial != "" {
+			t.Error("Expected rotation to fail without a new device")
+		}
+	})
+
+	t.Run("old device kept when MFA verification fails", func(t *testing.T) {
+		client := &mockIAMClient{
+			createVirtualMFADeviceFunc: func(ctx context.Context, input *iam.CreateVirtualMFADeviceInput) (*iam.CreateVirtualMFADeviceOutput, error) {
+				return &iam.CreateVirtualMFADeviceOutput{
+					VirtualMFADevice: &types.VirtualMFADevice{SerialNumber: aws.String("arn:aws:iam::123456789012:mfa/new")},
+				}, nil
+			},
+			enableMFADeviceFunc: func(ctx context.Context, input *iam.EnableMFADeviceInput) (*iam.EnableMFADeviceOutput, error) {
+				return &iam.EnableMFADeviceOutput{}, nil
+			},
+			deactivateMFADeviceFunc: func(ctx context.Context, input *iam.DeactivateMFADeviceInput) (*iam.DeactivateMFADeviceOutput, error) {
+				t.Error("Old device must not be deactivated without MFA")
+				return nil, nil
+			},
+		}
+
+		newSerial, err := rotateMFA(context.Background(), client, "testuser", oldDevice, enrollOptions{
+			DeviceName: "new", ShowSeed: true, Out: io.Discard, ReadCodes: readCodes,
+		}, func(ctx context.Context, newSerial string) (mfaAPI, error) {
+			return nil, errors.New("invalid MFA code")
 		})
-		if deleteErr != nil {
-			// Log but don't return this error as we're already handling another error
+		if err == nil || newSerial != "arn:aws:iam::123456789012:mfa/new" {
+			t.Errorf("Expected the new device to be kept with an error, got %q, %v", newSerial, err)
 		}
-		return "", err
+	})
+}
+
+func TestCheckClockSkewUnmeasured(t *testing.T) {
+	client := &mockIAMClient{
+		listMFADevicesFunc: func(ctx context.Context, input *iam.ListMFADevicesInput) (*iam.ListMFADevicesOutput, error) {
+			return &iam.ListMFADevicesOutput{}, nil
+		},
 	}
 
-	// Return the QR code URI (convert byte array to string)
-	return string(deviceResult.VirtualMFADevice.QRCodePNG), nil
+	// A response without server time must not read as zero skew
+	_, measured, err := checkClockSkew(context.Background(), client, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if measured {
+		t.Error("Expected skew to be unmeasured without response metadata")
+	}
 }
 
-// getMFAStatusWithClient is a testable version of getMFAStatus that accepts a mock client
-func getMFAStatusWithClient(ctx context.Context, client *mockIAMClient, username *string) (*MFAStatus, error) {
-	// List MFA devices
-	listInput := &iam.ListMFADevicesInput{
-		UserName: username,
+func TestResyncMFA(t *testing.T) {
+	var resyncInput *iam.ResyncMFADeviceInput
+	client := &mockIAMClient{
+		resyncMFADeviceFunc: func(ctx context.Context, input *iam.ResyncMFADeviceInput) (*iam.ResyncMFADeviceOutput, error) {
+			resyncInput = input
+			return &iam.ResyncMFADeviceOutput{}, nil
+		},
 	}
 
-	listResult, err := client.ListMFADevices(ctx, listInput)
+	err := resyncMFA(context.Background(), client, "bob", "GAHT12345678", "123456", "654321")
 	if err != nil {
-		return nil, err
+		t.Fatalf("Expected resync to succeed, got %v", err)
+	}
+	if *resyncInput.UserName != "bob" || *resyncInput.SerialNumber != "GAHT12345678" {
+		t.Errorf("Unexpected resync input: %+v", resyncInput)
 	}
 
-	// Check if any MFA devices exist
-	if len(listResult.MFADevices) == 0 {
-		return &MFAStatus{
-			Enabled: false,
-			Status:  "Disabled",
-			Device:  "None",
-		}, nil
+	client.resyncMFADeviceFunc = func(ctx context.Context, input *iam.ResyncMFADeviceInput) (*iam.ResyncMFADeviceOutput, error) {
+		return nil, &smithy.GenericAPIError{Code: "InvalidAuthenticationCode", Message: "invalid"}
 	}
+	err = resyncMFA(context.Background(), client, "bob", "GAHT12345678", "123456", "654321")
+	if err == nil || !strings.Contains(err.Error(), "consecutive") {
+		t.Errorf("Expected guidance about consecutive codes, got %v", err)
+	}
+}
 
-	// Use the first MFA device (assuming only one)
-	device := listResult.MFADevices[0]
+// mockReportClient serves a fixed set of users for the MFA report
+type mockReportClient struct {
+	users    map[string][]types.MFADevice
+	console  map[string]bool
+	keys     map[string][]types.StatusType
+	pageSize int
+}
 
-	return &MFAStatus{
-		Enabled:  true,
-		Status:   "Enabled",
-		Device:   *device.SerialNumber,
-		Enrolled: *device.EnableDate,
-	}, nil
-}
\ No newline at end of file
+func (m *mockReportClient) ListUsers(ctx context.Context, input *iam.ListUsersInput, optFns ...func(*iam.Options)) (*iam.ListUsersOutput, error) {
+	var names []string
+	for name := range m.users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	start := 0
+	if input.Marker != nil {
+		start, _ = strconv.Atoi(*input.Marker)
+	}
+	end := start + m.pageSize
+	if end > len(names) {
+		end = len(names)
+	}
+
+	output := &iam.ListUsersOutput{}
+	for _, name := range names[start:end] {
+		output.Users = append(output.Users, types.User{UserName: aws.String(name)})
+	}
+	if end < len(names) {
+		output.IsTruncated = true
+		output.Marker = aws.String(strconv.Itoa(end))
+	}
+	return output, nil
+}
+
+func (m *mockReportClient) ListMFADevices(ctx context.Context, input *iam.ListMFADevicesInput, optFns ...func(*iam.Options)) (*iam.ListMFADevicesOutput, error) {
+	return &iam.ListMFADevicesOutput{MFADevices: m.users[aws.ToString(input.UserName)]}, nil
+}
+
+func (m *mockReportClient) GetLoginProfile(ctx context.Context, input *iam.GetLoginProfileInput, optFns ...func(*iam.Options)) (*iam.GetLoginProfileOutput, error) {
+	if !m.console[aws.ToString(input.UserName)] {
+		return nil, &types.NoSuchEntityException{Message: aws.String("Login Profile cannot be found")}
+	}
+	return &iam.GetLoginProfileOutput{}, nil
+}
+
+func (m *mockReportClient) ListAccessKeys(ctx context.Context, input *iam.ListAccessKeysInput, optFns ...func(*iam.Options)) (*iam.ListAccessKeysOutput, error) {
+	output := &iam.ListAccessKeysOutput{}
+	for _, status := range m.keys[aws.ToString(input.UserName)] {
+		output.AccessKeyMetadata = append(output.AccessKeyMetadata, types.AccessKeyMetadata{Status: status})
+	}
+	return output, nil
+}
+
+func TestBuildMFAReport(t *testing.T) {
+	client := &mockReportClient{
+		users: map[string][]types.MFADevice{
+			"alice": {
+				{SerialNumber: aws.String("arn:aws:iam::123456789012:mfa/alice"), EnableDate: aws.Time(time.Now().AddDate(0, 0, -200))},
+				{SerialNumber: aws.String("arn:aws:iam::123456789012:u2f/user/alice/key"), EnableDate: aws.Time(time.Now().AddDate(0, 0, -10))},
+			},
+			"bob":   nil,
+			"carol": nil,
+		},
+		console:  map[string]bool{"alice": true, "bob": true},
+		keys:     map[string][]types.StatusType{"carol": {types.StatusTypeActive, types.StatusTypeInactive}},
+		pageSize: 2,
+	}
+
+	report, err := buildMFAReport(context.Background(), client, ageThresholds{WarnAfterDays: 75, FailAfterDays: 90}, 2)
+	if err != nil {
+		t.Fatalf("buildMFAReport() error = %v", err)
+	}
+
+	if len(report) != 3 {
+		t.Fatalf("expected 3 users, got %d", len(report))
+	}
+
+	alice, bob, carol := report[0], report[1], report[2]
+	if alice.UserName != "alice" || alice.DeviceCount != 2 || alice.Status != StatusOK {
+		t.Errorf("unexpected entry for alice: %+v", alice)
+	}
+	if !reflect.DeepEqual(alice.DeviceTypes, []string{DeviceTypeVirtual, DeviceTypeFIDO}) {
+		t.Errorf("unexpected device types for alice: %v", alice.DeviceTypes)
+	}
+	if alice.NewestAgeDays == nil || *alice.NewestAgeDays != 10 {
+		t.Errorf("expected newest device age of 10 days, got %v", alice.NewestAgeDays)
+	}
+	if bob.Status != StatusViolation || !bob.ConsoleAccess {
+		t.Errorf("expected bob to be flagged, got %+v", bob)
+	}
+	if carol.Status != StatusOK || carol.ConsoleAccess || carol.ActiveKeys != 1 {
+		t.Errorf("unexpected entry for carol: %+v", carol)
+	}
+	if report.Count(StatusViolation) != 1 || report.Status() != StatusViolation {
+		t.Errorf("expected 1 violation, got %d", report.Count(StatusViolation))
+	}
+}
+
+func TestProvisionSealedSeed(t *testing.T) {
+	t.Setenv("IAMCTL_HOME", t.TempDir())
+	dir := t.TempDir()
+
+	pubPath := filepath.Join(dir, "bob.pub")
+	if err := seedbox.GenerateKey("bob", pubPath); err != nil {
+		t.Fatal(err)
+	}
+	recipient, err := seedbox.ReadPublicKey(pubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var createInput *iam.CreateVirtualMFADeviceInput
+	var enableInput *iam.EnableMFADeviceInput
+	client := &mockIAMClient{
+		listMFADevicesFunc: func(ctx context.Context, input *iam.ListMFADevicesInput) (*iam.ListMFADevicesOutput, error) {
+			return &iam.ListMFADevicesOutput{}, nil
+		},
+		createVirtualMFADeviceFunc: func(ctx context.Context, input *iam.CreateVirtualMFADeviceInput) (*iam.CreateVirtualMFADeviceOutput, error) {
+			createInput = input
+			return &iam.CreateVirtualMFADeviceOutput{
+				VirtualMFADevice: &types.VirtualMFADevice{
+					SerialNumber:     aws.String("arn:aws:iam::123456789012:mfa/bob"),
+					Base32StringSeed: []byte("JBSWY3DPEHPK3PXP"),
+				},
+			}, nil
+		},
+		enableMFADeviceFunc: func(ctx context.Context, input *iam.EnableMFADeviceInput) (*iam.EnableMFADeviceOutput, error) {
+			enableInput = input
+			return &iam.EnableMFADeviceOutput{}, nil
+		},
+	}
+
+	seedOut := filepath.Join(dir, "bob.seed")
+	opts := enrollOptions{
+		DeviceName: "iamctl-bob",
+		Out:        io.Discard,
+		SeedOut:    seedOut,
+		SeedFormat: SeedFormatBase32,
+		Recipient:  &recipient,
+		Tags:       []types.Tag{{Key: aws.String("iamctl:provisioned-by"), Value: aws.String("arn:aws:iam::123456789012:user/helpdesk")}},
+		ReadCodes: func() (string, string, error) {
+			return "123456", "654321", nil
+		},
+	}
+	if _, err := enableMFA(context.Background(), client, aws.String("bob"), opts); err != nil {
+		t.Fatalf("enableMFA() error = %v", err)
+	}
+
+	if aws.ToString(enableInput.UserName) != "bob" {
+		t.Errorf("device enabled for %q, want bob", aws.ToString(enableInput.UserName))
+	}
+	if len(createInput.Tags) != 1 {
+		t.Errorf("expected the device to be tagged, got %v", createInput.Tags)
+	}
+
+	info, err := os.Stat(seedOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("seed file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	data, _ := os.ReadFile(seedOut)
+	if bytes.Contains(data, []byte("JBSWY3DPEHPK3PXP")) {
+		t.Fatal("sealed seed file contains the plaintext seed")
+	}
+	key, err := seedbox.LoadKey("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	contentType, payload, err := seedbox.Open(key, data)
+	if err != nil || contentType != seedbox.ContentSeed || string(payload) != "JBSWY3DPEHPK3PXP\n" {
+		t.Errorf("Open() = %q, %q, %v", contentType, payload, err)
+	}
+
+	// A failed activation deletes the device and its seed file, and an
+	// existing file is never overwritten
+	client.enableMFADeviceFunc = func(ctx context.Context, input *iam.EnableMFADeviceInput) (*iam.EnableMFADeviceOutput, error) {
+		return nil, errors.New("InvalidAuthenticationCode")
+	}
+	if _, err := enableMFA(context.Background(), client, aws.String("bob"), opts); err == nil {
+		t.Fatal("expected an error when the seed file already exists")
+	}
+	if _, err := os.Stat(seedOut); err != nil {
+		t.Error("existing seed file was removed")
+	}
+
+	os.Remove(seedOut)
+	if _, err := enableMFA(context.Background(), client, aws.String("bob"), opts); err == nil {
+		t.Fatal("expected activation to fail")
+	}
+	if _, err := os.Stat(seedOut); !os.IsNotExist(err) {
+		t.Error("seed file of the deleted device was left behind")
+	}
+}
+
+func TestAgeThresholds(t *testing.T) {
+	thresholds := ageThresholds{WarnAfterDays: 75, FailAfterDays: 90}
+
+	tests := []struct {
+		age       int
+		status    string
+		remaining int
+	}{
+		{0, StatusOK, 90},
+		{74, StatusOK, 16},
+		{75, StatusWarning, 15},
+		{89, StatusWarning, 1},
+		{90, StatusViolation, 0},
+		{120, StatusViolation, -30},
+	}
+
+	for _, tc := range tests {
+		status, remaining := thresholds.Evaluate(tc.age)
+		if status != tc.status || remaining != tc.remaining {
+			t.Errorf("Evaluate(%d) = %s, %d; want %s, %d", tc.age, status, remaining, tc.status, tc.remaining)
+		}
+	}
+
+	if worseStatus(StatusWarning, StatusOK) != StatusWarning || worseStatus(StatusWarning, StatusViolation) != StatusViolation {
+		t.Error("worseStatus did not pick the more severe status")
+	}
+	if err := statusError(StatusWarning, errors.New("warn")); exitcode.FromError(err) != exitcode.Warning {
+		t.Errorf("warning exit code = %d, want %d", exitcode.FromError(err), exitcode.Warning)
+	}
+	if err := statusError(StatusOK, errors.New("ok")); err != nil {
+		t.Errorf("statusError(ok) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,105 @@
+package mfa
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+)
+
+// qrQuietZone is the number of light modules printed around the code
+const qrQuietZone = 2
+
+// decodeQRModules recovers the module grid from the QR code PNG returned by
+// CreateVirtualMFADevice. The module size is measured from the top-left
+// finder pattern, which is always 7 modules wide.
+func decodeQRModules(data []byte) ([][]bool, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode QR code: %w", err)
+	}
+
+	bounds := img.Bounds()
+	dark := func(x, y int) bool {
+		r, g, b, _ := img.At(x, y).RGBA()
+		return (r+g+b)/3 < 0x8000
+	}
+
+	// Locate the bounding box of the dark modules
+	minX, minY, maxX, maxY := bounds.Max.X, bounds.Max.Y, -1, -1
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if dark(x, y) {
+				minX, minY = min(minX, x), min(minY, y)
+				maxX, maxY = max(maxX, x), max(maxY, y)
+			}
+		}
+	}
+	if maxX < 0 {
+		return nil, fmt.Errorf("QR code image is blank")
+	}
+
+	// The first row of the finder pattern is a run of 7 dark modules
+	run := 0
+	for x := minX; x <= maxX && dark(x, minY); x++ {
+		run++
+	}
+	moduleSize := float64(run) / 7
+	if moduleSize < 1 {
+		return nil, fmt.Errorf("QR code image is too small")
+	}
+
+	size := int(math.Round(float64(maxX-minX+1) / moduleSize))
+	if size < 21 || (size-17)%4 != 0 {
+		return nil, fmt.Errorf("unexpected QR code size %d", size)
+	}
+
+	modules := make([][]bool, size)
+	for row := range modules {
+		modules[row] = make([]bool, size)
+		for col := range modules[row] {
+			x := minX + int((float64(col)+0.5)*moduleSize)
+			y := minY + int((float64(row)+0.5)*moduleSize)
+			modules[row][col] = image.Pt(x, y).In(bounds) && dark(x, y)
+		}
+	}
+
+	return modules, nil
+}
+
+// renderQRModules draws the module grid with half-block characters, two
+// module rows per line. Light modules are drawn, so the code reads correctly
+// on dark terminal backgrounds.
+func renderQRModules(w io.Writer, modules [][]bool) {
+	size := len(modules)
+	light := func(row, col int) bool {
+		row, col = row-qrQuietZone, col-qrQuietZone
+		if row < 0 || col < 0 || row >= size || col >= size {
+			return true
+		}
+		return !modules[row][col]
+	}
+
+	total := size + 2*qrQuietZone
+	for row := 0; row < total; row += 2 {
+		var line strings.Builder
+		for col := 0; col < total; col++ {
+			top := light(row, col)
+			bottom := row+1 < total && light(row+1, col)
+			switch {
+			case top && bottom:
+				line.WriteString("█")
+			case top:
+				line.WriteString("▀")
+			case bottom:
+				line.WriteString("▄")
+			default:
+				line.WriteString(" ")
+			}
+		}
+		fmt.Fprintln(w, line.String())
+	}
+}
@@ -0,0 +1,242 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/spf13/cobra"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/executor"
+	"github.com/yourusername/iamctl/internal/output"
+)
+
+// reportAPI is the subset of the IAM client used by the MFA report
+type reportAPI interface {
+	iam.ListUsersAPIClient
+	mfaDeviceLister
+	GetLoginProfile(context.Context, *iam.GetLoginProfileInput, ...func(*iam.Options)) (*iam.GetLoginProfileOutput, error)
+	ListAccessKeys(context.Context, *iam.ListAccessKeysInput, ...func(*iam.Options)) (*iam.ListAccessKeysOutput, error)
+}
+
+// UserMFAReport is the MFA posture of a single IAM user
+type UserMFAReport struct {
+	UserName      string   `json:"user_name"`
+	DeviceCount   int      `json:"device_count"`
+	DeviceTypes   []string `json:"device_types"`
+	NewestAgeDays *int     `json:"newest_device_age_days"`
+	DaysRemaining *int     `json:"days_until_rotation"`
+	ConsoleAccess bool     `json:"console_access"`
+	ActiveKeys    int      `json:"active_access_keys"`
+	Status        string   `json:"status"`
+	Reason        string   `json:"reason,omitempty"`
+}
+
+// MFAReport is the account-wide MFA compliance report
+type MFAReport []UserMFAReport
+
+// Table implements output.Tabular
+func (r MFAReport) Table() output.Table {
+	table := output.Table{
+		Headers: []string{"USER", "DEVICES", "TYPES", "NEWEST AGE", "DUE IN", "CONSOLE", "ACTIVE KEYS", "STATUS", "REASON"},
+	}
+	for _, user := range r {
+		deviceTypes, age, due := "-", "-", "-"
+		if len(user.DeviceTypes) > 0 {
+			deviceTypes = strings.Join(user.DeviceTypes, ", ")
+		}
+		if user.NewestAgeDays != nil {
+			age = fmt.Sprintf("%dd", *user.NewestAgeDays)
+			due = fmt.Sprintf("%dd", *user.DaysRemaining)
+		}
+		table.Rows = append(table.Rows, []string{
+			user.UserName,
+			strconv.Itoa(user.DeviceCount),
+			deviceTypes,
+			age,
+			due,
+			strconv.FormatBool(user.ConsoleAccess),
+			strconv.Itoa(user.ActiveKeys),
+			user.Status,
+			user.Reason,
+		})
+	}
+	return table
+}
+
+// Count returns the number of users with the given status
+func (r MFAReport) Count(status string) int {
+	count := 0
+	for _, user := range r {
+		if user.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Status returns the worst status in the report
+func (r MFAReport) Status() string {
+	status := StatusOK
+	for _, user := range r {
+		status = worseStatus(status, user.Status)
+	}
+	return status
+}
+
+// NewReportCommand creates the MFA report command
+func NewReportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report MFA compliance for every IAM user in the account",
+		Long: `List every IAM user with their MFA device count and types, the age of
+their newest device, and whether they have console access or active access
+keys. Users with console access and no MFA device are violations, as are
+users whose newest device is older than --fail-after days; devices older
+than --warn-after days are warnings. The thresholds are shared with
+'mfa status' and default to the mfa section of ~/.iamctl/config.yaml.
+
+The command exits with status 0 when every user is ok, 3 when there are
+only warnings and 2 when there are violations, so it can gate CI pipelines.`,
+		// A failing gate prints its findings once, without the usage text
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get profile from flags or environment
+			profile, _ := cmd.Flags().GetString("profile")
+			format, _ := cmd.Flags().GetString("output")
+			workers, _ := cmd.Flags().GetInt("concurrency")
+
+			if err := output.ValidateFormat(format); err != nil {
+				return err
+			}
+			thresholds, err := thresholdsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Walking a large account takes longer than a single call
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			// Create IAM client
+			client, err := awssdk.NewIAMClient(profile)
+			if err != nil {
+				return handleMFAErrors(err)
+			}
+
+			// Build and render the report
+			report, err := buildMFAReport(ctx, client, thresholds, workers)
+			if err != nil {
+				return fmt.Errorf("❌ MFA report failed: %v", err)
+			}
+
+			if err := output.Render(os.Stdout, format, report); err != nil {
+				return err
+			}
+
+			return statusError(report.Status(), fmt.Errorf("❌ MFA report: %d violation(s), %d warning(s)",
+				report.Count(StatusViolation), report.Count(StatusWarning)))
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().StringP("output", "o", "", "Output format (text, csv or json)")
+	cmd.Flags().Int("concurrency", executor.DefaultWorkers, "Number of users to inspect in parallel")
+	addThresholdFlags(cmd)
+
+	return cmd
+}
+
+// buildMFAReport inspects every IAM user in the account, sorted by name
+func buildMFAReport(ctx context.Context, client reportAPI, thresholds ageThresholds, workers int) (MFAReport, error) {
+	// 1. List every user
+	var users []types.User
+	paginator := iam.NewListUsersPaginator(client, &iam.ListUsersInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+		users = append(users, page.Users...)
+	}
+
+	// 2. Inspect users in parallel
+	results, errs := executor.Map(ctx, users, workers, func(ctx context.Context, user types.User) (UserMFAReport, error) {
+		return inspectUserMFA(ctx, client, thresholds, aws.ToString(user.UserName))
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	report := MFAReport(results)
+	sort.Slice(report, func(i, j int) bool { return report[i].UserName < report[j].UserName })
+	return report, nil
+}
+
+// inspectUserMFA collects the MFA, console and access key state of a user
+func inspectUserMFA(ctx context.Context, client reportAPI, thresholds ageThresholds, username string) (UserMFAReport, error) {
+	user := UserMFAReport{UserName: username, Status: StatusOK}
+
+	devices, err := listMFADevices(ctx, client, aws.String(username))
+	if err != nil {
+		return user, fmt.Errorf("%s: %w", username, err)
+	}
+	user.DeviceCount = len(devices)
+	seen := map[string]bool{}
+	for _, device := range devices {
+		if !seen[device.Type] {
+			seen[device.Type] = true
+			user.DeviceTypes = append(user.DeviceTypes, device.Type)
+		}
+		if age := device.AgeDays(); user.NewestAgeDays == nil || age < *user.NewestAgeDays {
+			user.NewestAgeDays = &age
+		}
+	}
+
+	// A missing login profile means the user cannot sign in to the console
+	_, err = client.GetLoginProfile(ctx, &iam.GetLoginProfileInput{UserName: aws.String(username)})
+	var noSuchEntity *types.NoSuchEntityException
+	switch {
+	case err == nil:
+		user.ConsoleAccess = true
+	case !errors.As(err, &noSuchEntity):
+		return user, fmt.Errorf("%s: failed to get login profile: %w", username, err)
+	}
+
+	keys, err := client.ListAccessKeys(ctx, &iam.ListAccessKeysInput{UserName: aws.String(username)})
+	if err != nil {
+		return user, fmt.Errorf("%s: failed to list access keys: %w", username, err)
+	}
+	for _, key := range keys.AccessKeyMetadata {
+		if key.Status == types.StatusTypeActive {
+			user.ActiveKeys++
+		}
+	}
+
+	if user.ConsoleAccess && user.DeviceCount == 0 {
+		user.Status = StatusViolation
+		user.Reason = "console access without MFA"
+	}
+
+	// Rotation is judged on the newest device, since rotating adds a new one
+	if user.NewestAgeDays != nil {
+		status, remaining := thresholds.Evaluate(*user.NewestAgeDays)
+		user.DaysRemaining = &remaining
+		if status != StatusOK && user.Status == StatusOK {
+			user.Status = status
+			user.Reason = fmt.Sprintf("newest MFA device is %d days old", *user.NewestAgeDays)
+		}
+	}
+
+	return user, nil
+}
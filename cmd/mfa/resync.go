@@ -0,0 +1,133 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/smithy-go"
+	"github.com/spf13/cobra"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+)
+
+// maxClockSkew is the largest local clock offset tolerated before resyncing.
+// TOTP codes change every 30 seconds, so a larger offset breaks software
+// authenticators running against the local clock.
+const maxClockSkew = 30 * time.Second
+
+// NewResyncCommand creates the MFA resync command
+func NewResyncCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resync",
+		Short: "Resynchronize a drifted MFA device",
+		Long: `Resynchronize an MFA device with AWS using two consecutive codes. The local
+clock is checked against AWS server time first, since a wrong local clock is
+a more common cause of rejected codes than a drifted device.
+
+Administrators can resync another user's device with --username.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get profile from flags or environment
+			profile, _ := cmd.Flags().GetString("profile")
+			username, _ := cmd.Flags().GetString("username")
+			serial, _ := cmd.Flags().GetString("serial")
+			ignoreSkew, _ := cmd.Flags().GetBool("ignore-clock-skew")
+
+			// Codes are entered interactively, so allow more than the usual 15 seconds
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			// Create IAM client
+			client, err := awssdk.NewIAMClient(profile)
+			if err != nil {
+				return handleMFAErrors(err)
+			}
+
+			// Default to the current user
+			if username == "" {
+				user, err := awssdk.GetCurrentUser(ctx, client)
+				if err != nil {
+					return handleMFAErrors(err)
+				}
+				username = *user.UserName
+			}
+
+			// Rule out the local clock before blaming the device
+			skew, measured, err := checkClockSkew(ctx, client, username)
+			if err != nil {
+				return fmt.Errorf("❌ Resync failed: %v", err)
+			}
+			if !measured {
+				fmt.Println("Warning: Clock skew unknown; AWS did not report its server time")
+			} else if skew > maxClockSkew || skew < -maxClockSkew {
+				if !ignoreSkew {
+					return fmt.Errorf("❌ Local clock is %s off from AWS server time. Fix the system clock (e.g. enable NTP) and retry, or pass --ignore-clock-skew for a hardware token", skew.Round(time.Second))
+				}
+				fmt.Printf("Warning: Local clock is %s off from AWS server time\n", skew.Round(time.Second))
+			}
+
+			// Pick the device to resync
+			device, err := selectDevice(ctx, client, username, serial)
+			if err != nil {
+				return fmt.Errorf("❌ Resync failed: %v", err)
+			}
+			if device.Type == DeviceTypeFIDO {
+				return fmt.Errorf("❌ Resync failed: FIDO security keys do not use time-based codes")
+			}
+
+			fmt.Printf("Resyncing %s for user %s\n", device.SerialNumber, username)
+			code1, code2, err := getConsecutiveCodes()
+			if err != nil {
+				return fmt.Errorf("❌ Resync failed: %v", err)
+			}
+
+			if err := resyncMFA(ctx, client, username, device.SerialNumber, code1, code2); err != nil {
+				return fmt.Errorf("❌ Resync failed: %v", err)
+			}
+
+			fmt.Println("✅ MFA device resynchronized")
+			return nil
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().String("username", "", "User whose device to resync (defaults to current user)")
+	cmd.Flags().String("serial", "", "Serial number of the device to resync (required with more than one device)")
+	cmd.Flags().Bool("ignore-clock-skew", false, "Resync even if the local clock is off from AWS server time")
+
+	return cmd
+}
+
+// checkClockSkew measures the local clock offset from AWS server time using
+// the response to a cheap read-only call. measured is false when the
+// response carried no server time.
+func checkClockSkew(ctx context.Context, client mfaAPI, username string) (skew time.Duration, measured bool, err error) {
+	output, err := client.ListMFADevices(ctx, &iam.ListMFADevicesInput{UserName: aws.String(username)})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to list MFA devices: %w", err)
+	}
+
+	skew, measured = awssdk.ClockSkew(output.ResultMetadata)
+	return skew, measured, nil
+}
+
+// resyncMFA resynchronizes a device with two consecutive codes
+func resyncMFA(ctx context.Context, client mfaAPI, username, serial, code1, code2 string) error {
+	_, err := client.ResyncMFADevice(ctx, &iam.ResyncMFADeviceInput{
+		UserName:            aws.String(username),
+		SerialNumber:        aws.String(serial),
+		AuthenticationCode1: aws.String(code1),
+		AuthenticationCode2: aws.String(code2),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "InvalidAuthenticationCode" {
+			return fmt.Errorf("AWS rejected the codes; enter two consecutive codes from the device")
+		}
+		return fmt.Errorf("failed to resync MFA device: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package mfa
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/prompt"
+)
+
+// NewRotateCommand creates the MFA rotate command
+func NewRotateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Replace an MFA device with a new virtual device",
+		Long: `Enroll a new virtual MFA device while the old one is still active, verify it
+with two consecutive codes, and only then deactivate and delete the old
+device. Removing the old device needs an MFA session like 'mfa disable', so
+you are asked for one more code from the new device (or a cached session is
+used). The user always has at least one working MFA device.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get profile from flags or environment
+			profile, _ := cmd.Flags().GetString("profile")
+			serial, _ := cmd.Flags().GetString("serial")
+			qrFile, _ := cmd.Flags().GetString("qr-file")
+			showSeed, _ := cmd.Flags().GetBool("show-seed")
+			deviceName, _ := cmd.Flags().GetString("device-name")
+
+			if qrFile != "" && showSeed {
+				return fmt.Errorf("--qr-file and --show-seed cannot be used together")
+			}
+			if deviceName != "" && !deviceNamePattern.MatchString(deviceName) {
+				return fmt.Errorf("invalid device name %q", deviceName)
+			}
+
+			// Enrollment waits on the user, so allow more than the usual 15 seconds
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			// Create IAM client
+			client, err := awssdk.NewIAMClient(profile)
+			if err != nil {
+				return handleMFAErrors(err)
+			}
+
+			// Get current user
+			user, err := awssdk.GetCurrentUser(ctx, client)
+			if err != nil {
+				return handleMFAErrors(err)
+			}
+
+			// Pick the device being replaced
+			oldDevice, err := selectDevice(ctx, client, *user.UserName, serial)
+			if err != nil {
+				return fmt.Errorf("❌ MFA rotation failed: %v", err)
+			}
+
+			if deviceName == "" {
+				deviceName = fmt.Sprintf("iamctl-%s-%d", *user.UserName, time.Now().Unix())
+			}
+
+			newSerial, err := rotateMFA(ctx, client, *user.UserName, oldDevice, enrollOptions{
+				DeviceName: deviceName,
+				QRFile:     qrFile,
+				ShowSeed:   showSeed,
+				Out:        os.Stdout,
+				ReadCodes:  getConsecutiveCodes,
+			}, func(ctx context.Context, newSerial string) (mfaAPI, error) {
+				// Prove possession of the new device before retiring the old one
+				session, err := awssdk.RequireMFA(ctx, profile, newSerial, func(ctx context.Context) (string, error) {
+					return prompt.Code("Enter the next code from the new device")
+				})
+				if err != nil {
+					return nil, err
+				}
+				return session.IAMClient(), nil
+			})
+			if newSerial == "" {
+				return fmt.Errorf("❌ MFA rotation failed: %v", err)
+			}
+			if err != nil {
+				// The new device works; only the old one could not be removed
+				return fmt.Errorf("❌ New device %s is active but the old device was not removed: %v", newSerial, err)
+			}
+
+			fmt.Printf("✅ MFA rotated. New device: %s\n", newSerial)
+			return nil
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().String("serial", "", "Serial number of the device to replace (required with more than one device)")
+	cmd.Flags().String("qr-file", "", "Write the QR code to this PNG file instead of the terminal")
+	cmd.Flags().Bool("show-seed", false, "Show the Base32 seed instead of a QR code")
+	cmd.Flags().String("device-name", "", "Name for the new virtual device (defaults to iamctl-<user>-<timestamp>)")
+
+	return cmd
+}
+
+// rotateMFA enrolls and verifies a new virtual device, then removes the old
+// one with the MFA-verified client from verify. It returns the new serial
+// whenever the new device was activated, even if removing the old device
+// failed.
+func rotateMFA(ctx context.Context, client mfaAPI, username string, oldDevice MFADevice, opts enrollOptions, verify func(ctx context.Context, newSerial string) (mfaAPI, error)) (string, error) {
+	// 1. Enroll and verify the replacement while the old device stays active
+	newSerial, err := enableMFA(ctx, client, &username, opts)
+	if err != nil {
+		return "", err
+	}
+
+	// 2. Deactivating a device requires MFA, as in 'mfa disable'
+	verified, err := verify(ctx, newSerial)
+	if err != nil {
+		return newSerial, fmt.Errorf("MFA verification failed: %w", err)
+	}
+
+	// 3. Retire the old device only after the new one is verified
+	if err := disableMFA(ctx, verified, username, oldDevice); err != nil {
+		return newSerial, err
+	}
+
+	return newSerial, nil
+}
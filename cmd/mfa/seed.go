@@ -0,0 +1,98 @@
+package mfa
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/seedbox"
+)
+
+// NewSeedCommand creates the MFA seed command used to receive devices
+// provisioned by an administrator
+func NewSeedCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Receive MFA devices provisioned for you by an administrator",
+	}
+
+	cmd.AddCommand(newSeedKeygenCommand())
+	cmd.AddCommand(newSeedOpenCommand())
+
+	return cmd
+}
+
+// newSeedKeygenCommand creates the mfa seed keygen command
+func newSeedKeygenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keygen <name>",
+		Short: "Create a key that administrators can seal MFA seeds to",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, _ := cmd.Flags().GetString("out")
+			if out == "" {
+				out = args[0] + ".pub"
+			}
+
+			if err := seedbox.GenerateKey(args[0], out); err != nil {
+				return fmt.Errorf("❌ Key generation failed: %v", err)
+			}
+
+			fmt.Printf("✅ Seed key %s created. Give %s to your administrator.\n", args[0], out)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("out", "", "Where to write the public key (default <name>.pub)")
+
+	return cmd
+}
+
+// newSeedOpenCommand creates the mfa seed open command
+func newSeedOpenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "open <sealed-file>",
+		Short: "Decrypt a sealed MFA seed and show it for your authenticator app",
+		Long: `Decrypt a seed file sealed to your key with 'mfa enable --recipient-key'.
+A sealed QR code is drawn in the terminal unless --qr-file is given; a sealed
+Base32 seed is printed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			qrFile, _ := cmd.Flags().GetString("qr-file")
+
+			// The file names the key it was sealed to
+			contentType, payload, err := seedbox.OpenFile(args[0])
+			if err != nil {
+				return fmt.Errorf("❌ Failed to open seed file: %v", err)
+			}
+
+			switch contentType {
+			case seedbox.ContentSeed:
+				fmt.Printf("Add this seed to your authenticator app: %s", payload)
+			case seedbox.ContentQR:
+				if qrFile != "" {
+					if err := os.WriteFile(qrFile, payload, 0600); err != nil {
+						return fmt.Errorf("❌ Failed to write QR code: %v", err)
+					}
+					fmt.Printf("QR code written to %s. Scan it with your authenticator app.\n", qrFile)
+					break
+				}
+				modules, err := decodeQRModules(payload)
+				if err != nil {
+					return fmt.Errorf("❌ %v (use --qr-file instead)", err)
+				}
+				fmt.Println("Scan this QR code with your authenticator app:")
+				renderQRModules(os.Stdout, modules)
+			default:
+				return fmt.Errorf("❌ Unknown seed content %q", contentType)
+			}
+
+			fmt.Println("Then read two consecutive codes back to your administrator.")
+			return nil
+		},
+	}
+
+	cmd.Flags().String("qr-file", "", "Write a sealed QR code to this PNG file instead of the terminal")
+
+	return cmd
+}
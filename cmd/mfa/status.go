@@ -3,23 +3,62 @@ package mfa
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
-	awssdk "github.com/yourusername/iamctl/internal/aws"
 	"github.com/spf13/cobra"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
 )
 
+// MFA device types
+const (
+	DeviceTypeVirtual  = "virtual"
+	DeviceTypeHardware = "hardware TOTP"
+	DeviceTypeFIDO     = "FIDO security key"
+)
+
+// mfaDeviceLister is the subset of the IAM client used to list MFA devices
+type mfaDeviceLister interface {
+	ListMFADevices(context.Context, *iam.ListMFADevicesInput, ...func(*iam.Options)) (*iam.ListMFADevicesOutput, error)
+}
+
+// maxMFADevices is the number of MFA devices IAM allows per user
+const maxMFADevices = 8
+
 // NewStatusCommand creates the MFA status command
 func NewStatusCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show MFA enrollment status",
-		Long:  `Display information about the current user's MFA enrollment status.`,
+		Long: `Display every MFA device registered to the current user with its type,
+enable date, age and the days left until rotation is due.
+
+Devices older than --warn-after days are flagged as warnings and devices
+older than --fail-after days as violations. The thresholds default to the
+mfa section of ~/.iamctl/config.yaml:
+
+  mfa:
+    warn_after_days: 75
+    fail_after_days: 90
+
+The command exits with status 0 when every device is ok, 3 when there are
+warnings and 2 when there are violations.`,
+		// Overdue devices are reported in the table; root prints the error once
+		SilenceUsage:  true,
+		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Get profile from flags or environment
 			profile, _ := cmd.Flags().GetString("profile")
 
+			thresholds, err := thresholdsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
 			// Create context with timeout
 			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 			defer cancel()
@@ -45,64 +84,129 @@ func NewStatusCommand() *cobra.Command {
 			// Display MFA status
 			fmt.Printf("User: %s\n", *user.UserName)
 			fmt.Printf("MFA Status: %s\n", mfaStatus.Status)
-			
-			if mfaStatus.Enabled {
-				fmt.Printf("MFA Device: %s\n", mfaStatus.Device)
-				fmt.Printf("Enrolled: %s\n", mfaStatus.Enrolled.Format("2006-01-02 15:04:05 MST"))
-				
-				// Check if rotation is needed (90 days)
-				if time.Since(mfaStatus.Enrolled) > 90*24*time.Hour {
-					// For enterprise security, we enforce rotation after 90 days
-					return fmt.Errorf("❌ MFA device requires rotation (older than 90 days)")
+
+			if !mfaStatus.Enabled {
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SERIAL\tTYPE\tENABLED\tAGE\tDUE IN\tSTATUS")
+			overall := StatusOK
+			var flagged []string
+			for _, device := range mfaStatus.Devices {
+				status, remaining := thresholds.Evaluate(device.AgeDays())
+				fmt.Fprintf(w, "%s\t%s\t%s\t%dd\t%dd\t%s\n",
+					device.SerialNumber,
+					device.Type,
+					device.Enrolled.Format("2006-01-02 15:04:05 MST"),
+					device.AgeDays(),
+					remaining,
+					status)
+
+				if status != StatusOK {
+					flagged = append(flagged, device.SerialNumber)
 				}
+				overall = worseStatus(overall, status)
 			}
+			w.Flush()
 
-			return nil
+			if overall == StatusOK {
+				return nil
+			}
+			due := "due soon"
+			if overall == StatusViolation {
+				due = "overdue"
+			}
+			return statusError(overall, fmt.Errorf("❌ MFA device rotation %s (warn after %dd, fail after %dd): %s",
+				due, thresholds.WarnAfterDays, thresholds.FailAfterDays, strings.Join(flagged, ", ")))
 		},
 	}
 
 	// Add flags
 	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	addThresholdFlags(cmd)
 
 	return cmd
 }
 
 // MFAStatus represents the MFA enrollment status
 type MFAStatus struct {
-	Enabled  bool
-	Status   string
-	Device   string
-	Enrolled time.Time
+	Enabled bool
+	Status  string
+	Devices []MFADevice
 }
 
-// getMFAStatus retrieves the MFA status for a user
-func getMFAStatus(ctx context.Context, client *iam.Client, username *string) (*MFAStatus, error) {
-	// List MFA devices
-	listInput := &iam.ListMFADevicesInput{
-		UserName: username,
-	}
+// MFADevice describes a single MFA device registered to a user
+type MFADevice struct {
+	SerialNumber string
+	Type         string
+	Enrolled     time.Time
+}
+
+// AgeDays returns the number of whole days since the device was enabled
+func (d MFADevice) AgeDays() int {
+	return int(time.Since(d.Enrolled).Hours() / 24)
+}
 
-	listResult, err := client.ListMFADevices(ctx, listInput)
+// getMFAStatus retrieves the MFA status for a user
+func getMFAStatus(ctx context.Context, client mfaAPI, username *string) (*MFAStatus, error) {
+	devices, err := listMFADevices(ctx, client, username)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list MFA devices: %w", err)
+		return nil, err
 	}
 
 	// Check if any MFA devices exist
-	if len(listResult.MFADevices) == 0 {
+	if len(devices) == 0 {
 		return &MFAStatus{
 			Enabled: false,
 			Status:  "Disabled",
-			Device:  "None",
 		}, nil
 	}
 
-	// Use the first MFA device (assuming only one)
-	device := listResult.MFADevices[0]
+	status := "Enabled"
+	if len(devices) > 1 {
+		status = fmt.Sprintf("Enabled (%d devices)", len(devices))
+	}
 
 	return &MFAStatus{
-		Enabled:  true,
-		Status:   "Enabled",
-		Device:   *device.SerialNumber,
-		Enrolled: *device.EnableDate,
+		Enabled: true,
+		Status:  status,
+		Devices: devices,
 	}, nil
 }
+
+// listMFADevices returns every MFA device registered to a user
+func listMFADevices(ctx context.Context, client mfaDeviceLister, username *string) ([]MFADevice, error) {
+	listResult, err := client.ListMFADevices(ctx, &iam.ListMFADevicesInput{
+		UserName: username,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MFA devices: %w", err)
+	}
+
+	devices := make([]MFADevice, 0, len(listResult.MFADevices))
+	for _, device := range listResult.MFADevices {
+		serial := aws.ToString(device.SerialNumber)
+		devices = append(devices, MFADevice{
+			SerialNumber: serial,
+			Type:         deviceType(serial),
+			Enrolled:     aws.ToTime(device.EnableDate),
+		})
+	}
+
+	return devices, nil
+}
+
+// deviceType infers the device type from its serial number. Virtual devices
+// have an mfa/ ARN, FIDO security keys a u2f/ ARN, and hardware TOTP tokens
+// use the serial printed on the token.
+func deviceType(serial string) string {
+	switch {
+	case strings.HasPrefix(serial, "arn:") && strings.Contains(serial, ":u2f/"):
+		return DeviceTypeFIDO
+	case strings.HasPrefix(serial, "arn:") && strings.Contains(serial, ":mfa/"):
+		return DeviceTypeVirtual
+	default:
+		return DeviceTypeHardware
+	}
+}
@@ -0,0 +1,93 @@
+package mfa
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/config"
+	"github.com/yourusername/iamctl/internal/exitcode"
+)
+
+// Compliance statuses, from best to worst
+const (
+	StatusOK        = "ok"
+	StatusWarning   = "warning"
+	StatusViolation = "violation"
+)
+
+// ageThresholds are the MFA device ages, in days, at which rotation is
+// coming due (warning) and overdue (violation)
+type ageThresholds struct {
+	WarnAfterDays int
+	FailAfterDays int
+}
+
+// Evaluate returns the status of a device of the given age and the days left
+// until rotation is due (negative when overdue)
+func (t ageThresholds) Evaluate(ageDays int) (string, int) {
+	remaining := t.FailAfterDays - ageDays
+	switch {
+	case ageDays >= t.FailAfterDays:
+		return StatusViolation, remaining
+	case ageDays >= t.WarnAfterDays:
+		return StatusWarning, remaining
+	default:
+		return StatusOK, remaining
+	}
+}
+
+// addThresholdFlags adds the MFA age threshold flags to a command
+func addThresholdFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("warn-after", 0, fmt.Sprintf("Warn when an MFA device is this many days old (default mfa.warn_after_days in config.yaml, or %d)", config.DefaultMFAWarnAfterDays))
+	cmd.Flags().Int("fail-after", 0, fmt.Sprintf("Fail when an MFA device is this many days old (default mfa.fail_after_days in config.yaml, or %d)", config.DefaultMFAFailAfterDays))
+}
+
+// thresholdsFromFlags resolves the thresholds from flags, falling back to
+// config.yaml and then the defaults
+func thresholdsFromFlags(cmd *cobra.Command) (ageThresholds, error) {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return ageThresholds{}, err
+	}
+
+	thresholds := ageThresholds{
+		WarnAfterDays: settings.MFA.WarnAfterDays,
+		FailAfterDays: settings.MFA.FailAfterDays,
+	}
+	if cmd.Flags().Changed("warn-after") {
+		thresholds.WarnAfterDays, _ = cmd.Flags().GetInt("warn-after")
+	}
+	if cmd.Flags().Changed("fail-after") {
+		thresholds.FailAfterDays, _ = cmd.Flags().GetInt("fail-after")
+	}
+
+	if thresholds.WarnAfterDays <= 0 || thresholds.FailAfterDays <= 0 {
+		return thresholds, fmt.Errorf("MFA age thresholds must be positive")
+	}
+	if thresholds.WarnAfterDays > thresholds.FailAfterDays {
+		return thresholds, fmt.Errorf("--warn-after (%d) must not exceed --fail-after (%d)", thresholds.WarnAfterDays, thresholds.FailAfterDays)
+	}
+	return thresholds, nil
+}
+
+// worseStatus returns the more severe of two statuses
+func worseStatus(a, b string) string {
+	rank := map[string]int{StatusOK: 0, StatusWarning: 1, StatusViolation: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// statusError maps an overall status to the command's exit: nil for ok,
+// exit code 3 for warnings and 2 for violations
+func statusError(status string, err error) error {
+	switch status {
+	case StatusViolation:
+		return exitcode.New(exitcode.Violation, err)
+	case StatusWarning:
+		return exitcode.New(exitcode.Warning, err)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/config"
+	"github.com/yourusername/iamctl/internal/hibp"
+)
+
+// addBreachFlags adds the flags of the offline breached-password check
+func addBreachFlags(cmd *cobra.Command) {
+	cmd.Flags().String("hibp-file", "", "Reject passwords found in this sorted Have I Been Pwned SHA-1 file (default passwords.hibp_file in config.yaml)")
+	cmd.Flags().Int("hibp-min-count", 0, "Reject passwords seen in at least this many breaches (default passwords.hibp_min_count in config.yaml, or 1)")
+}
+
+// breachCheck is the breached-password check configured by flags and
+// config.yaml. An empty File disables it.
+type breachCheck struct {
+	File     string
+	MinCount int
+}
+
+// breachCheckFromFlags reads the flags from addBreachFlags, falling back to
+// config.yaml
+func breachCheckFromFlags(cmd *cobra.Command) (breachCheck, error) {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return breachCheck{}, err
+	}
+
+	check := breachCheck{File: settings.Passwords.HIBPFile, MinCount: settings.Passwords.HIBPMinCount}
+	if cmd.Flags().Changed("hibp-file") {
+		check.File, _ = cmd.Flags().GetString("hibp-file")
+	}
+	if cmd.Flags().Changed("hibp-min-count") {
+		check.MinCount, _ = cmd.Flags().GetInt("hibp-min-count")
+	}
+	if check.MinCount < 1 {
+		return breachCheck{}, fmt.Errorf("--hibp-min-count must be at least 1")
+	}
+
+	// Fail before any prompt rather than after MFA
+	if check.File != "" {
+		if _, err := os.Stat(check.File); err != nil {
+			return breachCheck{}, fmt.Errorf("breached password list: %w", err)
+		}
+	}
+	return check, nil
+}
+
+// Check rejects a password seen in at least MinCount breaches
+func (c breachCheck) Check(password string) error {
+	if c.File == "" {
+		return nil
+	}
+
+	list, err := hibp.Open(c.File)
+	if err != nil {
+		return fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer list.Close()
+
+	count, err := list.Count(password)
+	if err != nil {
+		return fmt.Errorf("failed to search breached password list: %w", err)
+	}
+	if count >= c.MinCount {
+		return fmt.Errorf("password appears in known data breaches %d time(s); choose another", count)
+	}
+	return nil
+}
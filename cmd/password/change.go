@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/spf13/cobra"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/passwordpolicy"
+	"github.com/yourusername/iamctl/internal/prompt"
+)
+
+// NewChangeCommand creates the password change command
+func NewChangeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "change",
+		Short: "Change your own IAM user password",
+		Long: `Change your own console password with iam:ChangePassword, which any IAM
+user allowed by the account policy can call without administrator rights.
+The new password is checked against the account password policy first, and
+against a local Have I Been Pwned list and a minimum strength score when
+they are configured.
+
+Without a terminal, give the current password in IAMCTL_CURRENT_PASSWORD and
+the new one in IAMCTL_PASSWORD, or both on consecutive lines of --password-fd.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get profile from flags or environment
+			profile, _ := cmd.Flags().GetString("profile")
+
+			breaches, err := breachCheckFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			minStrength, err := strengthCheckFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			// 1. Read the current and new passwords before the API deadline starts
+			oldPassword, err := prompt.CurrentPassword("Enter current password")
+			if err != nil {
+				return fmt.Errorf("❌ Change failed: failed to read password: %v", err)
+			}
+			newPassword, err := prompt.NewPassword("Enter new password")
+			if err != nil {
+				return fmt.Errorf("❌ Change failed: failed to read password: %v", err)
+			}
+			if newPassword == oldPassword {
+				return fmt.Errorf("❌ Change failed: the new password must differ from the current one")
+			}
+
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			// Create IAM client
+			client, err := awssdk.NewIAMClient(profile)
+			if err != nil {
+				return fmt.Errorf("❌ Change failed: Invalid credentials")
+			}
+
+			// 2. Validate against the account password policy
+			policy, err := passwordpolicy.Load(ctx, client)
+			if err != nil {
+				return fmt.Errorf("❌ Change failed: %v", err)
+			}
+			if err := checkPassword(policy, newPassword); err != nil {
+				return fmt.Errorf("❌ Change failed: %v", err)
+			}
+			if err := breaches.Check(newPassword); err != nil {
+				return fmt.Errorf("❌ Change failed: %v", err)
+			}
+			username := currentUserName(ctx, client)
+			if err := minStrength.Check(ctx, client, newPassword, username); err != nil {
+				return fmt.Errorf("❌ Change failed: %v", err)
+			}
+			for _, notice := range policy.Notices() {
+				fmt.Fprintf(os.Stderr, "Note: %s\n", notice)
+			}
+
+			// 3. Learn whether a new password is required at next sign-in
+			required := resetRequired(ctx, client, username)
+			if required {
+				fmt.Fprintln(os.Stderr, "Note: your password has expired or must be reset at next sign-in; changing it clears that requirement")
+			}
+
+			// 4. Change the password with the caller's own credentials
+			if err := changePassword(ctx, client, oldPassword, newPassword); err != nil {
+				return handlePasswordChangeErrors(err, policy, required)
+			}
+
+			if required {
+				fmt.Println("✅ Password changed; the reset requirement is cleared")
+				return nil
+			}
+			fmt.Println("✅ Password changed")
+			return nil
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	addBreachFlags(cmd)
+	addStrengthFlags(cmd)
+
+	return cmd
+}
+
+// changePasswordAPI is the subset of the IAM client used to change the
+// caller's password
+type changePasswordAPI interface {
+	ChangePassword(context.Context, *iam.ChangePasswordInput, ...func(*iam.Options)) (*iam.ChangePasswordOutput, error)
+}
+
+// changePassword replaces the caller's password
+func changePassword(ctx context.Context, client changePasswordAPI, oldPassword, newPassword string) error {
+	_, err := client.ChangePassword(ctx, &iam.ChangePasswordInput{
+		OldPassword: aws.String(oldPassword),
+		NewPassword: aws.String(newPassword),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+	return nil
+}
+
+// getLoginProfileAPI is the subset of the IAM client used to read a user's
+// login profile
+type getLoginProfileAPI interface {
+	GetLoginProfile(context.Context, *iam.GetLoginProfileInput, ...func(*iam.Options)) (*iam.GetLoginProfileOutput, error)
+}
+
+// resetRequired reports whether the user's login profile requires a new
+// password at next sign-in. ChangePassword reports no error for this, so it
+// is read from the login profile; failures to read it count as not required.
+func resetRequired(ctx context.Context, client getLoginProfileAPI, username string) bool {
+	if username == "" {
+		return false
+	}
+	output, err := client.GetLoginProfile(ctx, &iam.GetLoginProfileInput{UserName: aws.String(username)})
+	if err != nil || output.LoginProfile == nil {
+		return false
+	}
+	return output.LoginProfile.PasswordResetRequired
+}
+
+// currentUserName returns the caller's user name, or "" when it cannot be
+// read
+func currentUserName(ctx context.Context, client *iam.Client) string {
+	user, err := awssdk.GetCurrentUser(ctx, client)
+	if err != nil {
+		return ""
+	}
+	return *user.UserName
+}
+
+// handlePasswordChangeErrors explains the failures a user can act on and
+// collapses the rest to the generic message. required is whether the login
+// profile demanded a new password before the change was attempted.
+func handlePasswordChangeErrors(err error, policy passwordpolicy.Policy, required bool) error {
+	var (
+		unmodifiable *types.EntityTemporarilyUnmodifiableException
+		violation    *types.PasswordPolicyViolationException
+		userType     *types.InvalidUserTypeException
+	)
+	switch {
+	case errors.As(err, &unmodifiable):
+		return fmt.Errorf("❌ Change failed: your login profile was changed moments ago; wait a few seconds and try again")
+	case errors.As(err, &violation):
+		return fmt.Errorf("❌ Change failed: IAM rejected the password under the %s (it may match a recent password)", policy.Describe())
+	case errors.As(err, &userType):
+		return fmt.Errorf("❌ Change failed: only IAM users can change their password; root and federated identities cannot")
+	case required && policy.HardExpiry:
+		// With hard expiry, users cannot replace an expired password themselves
+		return fmt.Errorf("❌ Change failed: your password has expired and the account policy does not let you replace it; ask an administrator to run 'iamctl password reset --username <you>'")
+	}
+	return fmt.Errorf("❌ Change failed: Invalid credentials")
+}
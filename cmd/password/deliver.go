@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/spf13/cobra"
+)
+
+// passwordDelivery says where a generated password goes. It is never printed
+// unless Show is set.
+type passwordDelivery struct {
+	OutputFile string
+	SecretName string
+	Show       bool
+}
+
+// secretsAPI is the subset of the Secrets Manager client used to deliver
+// generated passwords
+type secretsAPI interface {
+	CreateSecret(context.Context, *secretsmanager.CreateSecretInput, ...func(*secretsmanager.Options)) (*secretsmanager.CreateSecretOutput, error)
+	DeleteSecret(context.Context, *secretsmanager.DeleteSecretInput, ...func(*secretsmanager.Options)) (*secretsmanager.DeleteSecretOutput, error)
+}
+
+// addDeliveryFlags adds the flags choosing where a generated password goes
+func addDeliveryFlags(cmd *cobra.Command) {
+	cmd.Flags().String("output-file", "", "Write the generated password to this new file (mode 0600)")
+	cmd.Flags().String("secret-name", "", "Store the generated password in AWS Secrets Manager under this name")
+	cmd.Flags().Bool("show", false, "Print the generated password on stdout")
+}
+
+// deliveryFromFlags reads the flags from addDeliveryFlags
+func deliveryFromFlags(cmd *cobra.Command) passwordDelivery {
+	var d passwordDelivery
+	d.OutputFile, _ = cmd.Flags().GetString("output-file")
+	d.SecretName, _ = cmd.Flags().GetString("secret-name")
+	d.Show, _ = cmd.Flags().GetBool("show")
+	return d
+}
+
+// checkGenerateFlags rejects generator and delivery flags without
+// --generate, and --generate without somewhere to deliver the password
+func checkGenerateFlags(cmd *cobra.Command, generate bool, d passwordDelivery) error {
+	if !generate {
+		for _, name := range []string{"length", "exclude-ambiguous", "output-file", "secret-name", "show"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s requires --generate", name)
+			}
+		}
+		return nil
+	}
+	if d.OutputFile == "" && d.SecretName == "" && !d.Show {
+		return fmt.Errorf("--generate needs --output-file, --secret-name or --show to deliver the password")
+	}
+	return nil
+}
+
+// deliver writes the password to the file and secret sinks. The returned
+// function removes what was written, for when the reset then fails.
+func (d passwordDelivery) deliver(ctx context.Context, client secretsAPI, username, password string) (func(), error) {
+	var undo []func()
+	undoAll := func() {
+		for _, fn := range undo {
+			fn()
+		}
+	}
+
+	if d.OutputFile != "" {
+		if err := writePasswordFile(d.OutputFile, password); err != nil {
+			return nil, err
+		}
+		path := d.OutputFile
+		undo = append(undo, func() { os.Remove(path) })
+	}
+
+	if d.SecretName != "" {
+		if err := storePasswordSecret(ctx, client, d.SecretName, username, password); err != nil {
+			undoAll()
+			return nil, err
+		}
+		name := d.SecretName
+		undo = append(undo, func() {
+			// Use a fresh deadline since ctx may already have expired
+			cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 15*time.Second)
+			defer cancel()
+			_, err := client.DeleteSecret(cleanupCtx, &secretsmanager.DeleteSecretInput{
+				SecretId:                   aws.String(name),
+				ForceDeleteWithoutRecovery: aws.Bool(true),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to delete secret %s: %v\n", name, err)
+			}
+		})
+	}
+
+	return undoAll, nil
+}
+
+// writePasswordFile creates path with mode 0600, refusing to overwrite an
+// existing file
+func writePasswordFile(path, password string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create password file: %w", err)
+	}
+	if _, err := fmt.Fprintln(f, password); err != nil {
+		f.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write password file: %w", err)
+	}
+	return f.Close()
+}
+
+// storePasswordSecret stores the password in a new Secrets Manager secret
+func storePasswordSecret(ctx context.Context, client secretsAPI, name, username, password string) error {
+	value, err := json.Marshal(map[string]string{"UserName": username, "Password": password})
+	if err != nil {
+		return err
+	}
+	_, err = client.CreateSecret(ctx, &secretsmanager.CreateSecretInput{
+		Name:         aws.String(name),
+		SecretString: aws.String(string(value)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store password in Secrets Manager: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/approval"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/prompt"
+)
+
+// NewDisableCommand creates the password disable command
+func NewDisableCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disable",
+		Short: "Remove console access by deleting the login profile",
+		Long: `Delete a user's login profile so they can no longer sign in to the
+console. Access keys are not affected. Asks for confirmation and a current
+MFA code.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get profile from flags or environment
+			profile, _ := cmd.Flags().GetString("profile")
+			username, _ := cmd.Flags().GetString("username")
+			mfaSerial, _ := cmd.Flags().GetString("mfa-serial")
+
+			// Look up the user under its own deadline; the prompts below wait
+			// on a human
+			lookupCtx, cancelLookup := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelLookup()
+
+			// Create IAM client
+			client, err := awssdk.NewIAMClient(profile)
+			if err != nil {
+				return handlePasswordDisableErrors(err)
+			}
+
+			// Get current user if username not provided
+			if username == "" {
+				user, err := awssdk.GetCurrentUser(lookupCtx, client)
+				if err != nil {
+					return handlePasswordDisableErrors(err)
+				}
+				username = *user.UserName
+			}
+
+			// In approval mode, write a signed request instead of disabling
+			if approval.Requested(cmd) {
+				path, err := approval.CreateFromFlags(cmd, "password.disable", map[string]string{
+					"username": username,
+				})
+				if err != nil {
+					return fmt.Errorf("❌ Approval request failed: %v", err)
+				}
+				fmt.Printf("✅ Approval request written to %s\n", path)
+				return nil
+			}
+
+			// Confirm before removing console access
+			question := fmt.Sprintf("Are you sure you want to remove console access for %s?", username)
+			if err := prompt.Confirm(question); err != nil {
+				return fmt.Errorf("❌ Operation cancelled: %v", err)
+			}
+
+			// Prove possession of a current MFA code
+			session, err := awssdk.RequireMFA(context.Background(), profile, mfaSerial, mfatoken.ForProfile(profile, prompt.MFACode))
+			if err != nil {
+				return fmt.Errorf("❌ Operation failed: Invalid credentials")
+			}
+
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			// Delete the login profile with the verified session
+			if err := disableConsoleAccess(ctx, session.IAMClient(), username); err != nil {
+				return fmt.Errorf("❌ Operation failed: %v", err)
+			}
+
+			fmt.Printf("✅ Console access removed for %s\n", username)
+			return nil
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().String("username", "", "User to remove console access from (defaults to current user)")
+	cmd.Flags().String("mfa-serial", "", "MFA device serial number (defaults to your only MFA device)")
+	approval.AddFlags(cmd)
+
+	return cmd
+}
+
+func init() {
+	approval.Register("password.disable", executeApprovedPasswordDisable)
+}
+
+// executeApprovedPasswordDisable removes console access for the user named
+// in an approved request
+func executeApprovedPasswordDisable(ctx context.Context, client *iam.Client, params map[string]string) error {
+	return disableConsoleAccess(ctx, client, params["username"])
+}
+
+// deleteLoginProfileAPI is the subset of the IAM client used to remove
+// console access
+type deleteLoginProfileAPI interface {
+	DeleteLoginProfile(context.Context, *iam.DeleteLoginProfileInput, ...func(*iam.Options)) (*iam.DeleteLoginProfileOutput, error)
+}
+
+// disableConsoleAccess deletes the user's login profile
+func disableConsoleAccess(ctx context.Context, client deleteLoginProfileAPI, username string) error {
+	_, err := client.DeleteLoginProfile(ctx, &iam.DeleteLoginProfileInput{
+		UserName: aws.String(username),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete login profile for %s: %w", username, err)
+	}
+	return nil
+}
+
+// handlePasswordDisableErrors converts SDK errors to user-friendly messages
+func handlePasswordDisableErrors(err error) error {
+	// Always return generic error message for security
+	return fmt.Errorf("❌ Operation failed: Invalid credentials")
+}
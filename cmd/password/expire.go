@@ -0,0 +1,408 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/audit"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/executor"
+	"github.com/yourusername/iamctl/internal/exitcode"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/output"
+	"github.com/yourusername/iamctl/internal/prompt"
+)
+
+// Results of expiring a user's password
+const (
+	ExpirePending = "to expire"
+	ExpireDone    = "expired"
+	ExpireSkipped = "skipped"
+	ExpireFailed  = "failed"
+)
+
+// expireAPI is the subset of the IAM client used to expire passwords
+type expireAPI interface {
+	iam.ListUsersAPIClient
+	iam.GetGroupAPIClient
+	ListUserTags(context.Context, *iam.ListUserTagsInput, ...func(*iam.Options)) (*iam.ListUserTagsOutput, error)
+	GetLoginProfile(context.Context, *iam.GetLoginProfileInput, ...func(*iam.Options)) (*iam.GetLoginProfileOutput, error)
+	UpdateLoginProfile(context.Context, *iam.UpdateLoginProfileInput, ...func(*iam.Options)) (*iam.UpdateLoginProfileOutput, error)
+}
+
+// ExpireResult is the outcome for a single user
+type ExpireResult struct {
+	UserName string `json:"user_name"`
+	Result   string `json:"result"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// ExpireReport lists the outcome for every matching user
+type ExpireReport []ExpireResult
+
+// Table implements output.Tabular
+func (r ExpireReport) Table() output.Table {
+	table := output.Table{Headers: []string{"USER", "RESULT", "DETAIL"}}
+	for _, user := range r {
+		table.Rows = append(table.Rows, []string{user.UserName, user.Result, user.Detail})
+	}
+	return table
+}
+
+// Count returns the number of users with the given result
+func (r ExpireReport) Count(result string) int {
+	count := 0
+	for _, user := range r {
+		if user.Result == result {
+			count++
+		}
+	}
+	return count
+}
+
+// Users returns the names of users with the given result
+func (r ExpireReport) Users(result string) []string {
+	var users []string
+	for _, user := range r {
+		if user.Result == result {
+			users = append(users, user.UserName)
+		}
+	}
+	return users
+}
+
+// Summary describes the report in one line
+func (r ExpireReport) Summary() string {
+	return fmt.Sprintf("%d expired, %d to expire, %d skipped, %d failed",
+		r.Count(ExpireDone), r.Count(ExpirePending), r.Count(ExpireSkipped), r.Count(ExpireFailed))
+}
+
+// expiryFilter narrows the users whose passwords are expired
+type expiryFilter struct {
+	Exclude map[string]bool
+	Tags    map[string]string
+}
+
+// NewExpireCommand creates the password expire command
+func NewExpireCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "expire",
+		Short: "Force users to choose a new console password at next sign-in",
+		Long: `Set PasswordResetRequired on the login profile of every user in a group,
+every user in the account, or every user listed in a file (one name per
+line). Users without console access or already required to reset are
+skipped, as are users named with --exclude and, with --tag, users missing
+any of the given tags.
+
+A current MFA code is asked for before the users are looked up, and the
+matching users are listed before a confirmation; --dry-run needs no MFA code
+and stops after listing them. The command exits with status 1 when any user
+could not be expired.`,
+		// Per-user failures are in the report; root prints the error once
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get profile from flags or environment
+			profile, _ := cmd.Flags().GetString("profile")
+			mfaSerial, _ := cmd.Flags().GetString("mfa-serial")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			format, _ := cmd.Flags().GetString("output")
+			workers, _ := cmd.Flags().GetInt("concurrency")
+
+			if err := output.ValidateFormat(format); err != nil {
+				return err
+			}
+			filter, err := expiryFilterFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Create IAM client
+			client, err := awssdk.NewIAMClient(profile)
+			if err != nil {
+				return fmt.Errorf("❌ Expire failed: Invalid credentials")
+			}
+
+			// 1. Prove possession of a current MFA code before reading user
+			// details, which an MFA-enforcing policy denies without one. A dry
+			// run only reads, with the caller's plain credentials.
+			if !dryRun {
+				session, err := awssdk.RequireMFA(context.Background(), profile, mfaSerial, mfatoken.ForProfile(profile, prompt.MFACode))
+				if err != nil {
+					return fmt.Errorf("❌ Expire failed: Invalid credentials")
+				}
+				client = session.IAMClient()
+			}
+
+			// Walking a large account takes longer than a single call
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			// 2. Collect the users and work out which to expire
+			users, err := expiryUsers(ctx, cmd, client)
+			if err != nil {
+				return fmt.Errorf("❌ Expire failed: %v", err)
+			}
+			report := planExpiry(ctx, client, users, filter, workers)
+
+			if report.Count(ExpirePending) == 0 || dryRun {
+				if err := output.Render(os.Stdout, format, report); err != nil {
+					return err
+				}
+				if dryRun {
+					fmt.Fprintf(os.Stderr, "Dry run: %s\n", report.Summary())
+				} else {
+					fmt.Fprintf(os.Stderr, "Nothing to expire: %s\n", report.Summary())
+				}
+				return expiryError(report)
+			}
+
+			// 3. Confirm the list of users
+			fmt.Fprintf(os.Stderr, "Users to expire: %s\n", strings.Join(report.Users(ExpirePending), ", "))
+			question := fmt.Sprintf("Require %d user(s) to reset their password?", report.Count(ExpirePending))
+			if err := prompt.Confirm(question); err != nil {
+				return fmt.Errorf("❌ Operation cancelled: %v", err)
+			}
+
+			// 4. Expire with the verified session and report
+			report = applyExpiry(ctx, client, report, workers)
+			if err := recordExpiry(ctx, client, report); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write audit log: %v\n", err)
+			}
+
+			if err := output.Render(os.Stdout, format, report); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "✅ %s\n", report.Summary())
+			return expiryError(report)
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().String("mfa-serial", "", "MFA device serial number (defaults to your only MFA device)")
+	cmd.Flags().String("group", "", "Expire the passwords of every user in this IAM group")
+	cmd.Flags().Bool("all-users", false, "Expire the passwords of every user in the account")
+	cmd.Flags().String("users-file", "", "Expire the passwords of the users listed in this file")
+	cmd.MarkFlagsMutuallyExclusive("group", "all-users", "users-file")
+	cmd.MarkFlagsOneRequired("group", "all-users", "users-file")
+	cmd.Flags().StringSlice("exclude", nil, "Users to leave alone (repeatable or comma-separated)")
+	cmd.Flags().StringSlice("tag", nil, "Only expire users with this KEY=VALUE tag (repeatable, all must match)")
+	cmd.Flags().Bool("dry-run", false, "List the users that would be expired without changing anything")
+	cmd.Flags().StringP("output", "o", "", "Output format (text, csv or json)")
+	cmd.Flags().Int("concurrency", executor.DefaultWorkers, "Number of users to process in parallel")
+
+	return cmd
+}
+
+// expiryFilterFromFlags reads --exclude and --tag
+func expiryFilterFromFlags(cmd *cobra.Command) (expiryFilter, error) {
+	exclude, _ := cmd.Flags().GetStringSlice("exclude")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+
+	filter := expiryFilter{Exclude: map[string]bool{}, Tags: map[string]string{}}
+	for _, name := range exclude {
+		filter.Exclude[name] = true
+	}
+	for _, tag := range tags {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok || key == "" {
+			return filter, fmt.Errorf("--tag must be KEY=VALUE, got %q", tag)
+		}
+		filter.Tags[key] = value
+	}
+	return filter, nil
+}
+
+// expiryUsers returns the user names selected by --group, --all-users or
+// --users-file
+func expiryUsers(ctx context.Context, cmd *cobra.Command, client expireAPI) ([]string, error) {
+	group, _ := cmd.Flags().GetString("group")
+	usersFile, _ := cmd.Flags().GetString("users-file")
+
+	var users []string
+	switch {
+	case group != "":
+		paginator := iam.NewGetGroupPaginator(client, &iam.GetGroupInput{GroupName: aws.String(group)})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get group %s: %w", group, err)
+			}
+			for _, user := range page.Users {
+				users = append(users, aws.ToString(user.UserName))
+			}
+		}
+	case usersFile != "":
+		return readUsersFile(usersFile)
+	default:
+		paginator := iam.NewListUsersPaginator(client, &iam.ListUsersInput{})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list users: %w", err)
+			}
+			for _, user := range page.Users {
+				users = append(users, aws.ToString(user.UserName))
+			}
+		}
+	}
+	return users, nil
+}
+
+// readUsersFile reads one user name per line, ignoring blank lines and
+// # comments
+func readUsersFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var users []string
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, _, _ := strings.Cut(scanner.Text(), "#")
+		name = strings.TrimSpace(name)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			users = append(users, name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return users, nil
+}
+
+// planExpiry decides, without changing anything, which users' passwords
+// would be expired
+func planExpiry(ctx context.Context, client expireAPI, users []string, filter expiryFilter, workers int) ExpireReport {
+	results, errs := executor.Map(ctx, users, workers, func(ctx context.Context, username string) (ExpireResult, error) {
+		return planUserExpiry(ctx, client, username, filter)
+	})
+
+	report := ExpireReport(results)
+	for i, err := range errs {
+		if err != nil {
+			report[i] = ExpireResult{UserName: users[i], Result: ExpireFailed, Detail: err.Error()}
+		}
+	}
+	return report
+}
+
+// planUserExpiry decides whether a single user's password would be expired
+func planUserExpiry(ctx context.Context, client expireAPI, username string, filter expiryFilter) (ExpireResult, error) {
+	result := ExpireResult{UserName: username, Result: ExpireSkipped}
+
+	if filter.Exclude[username] {
+		result.Detail = "excluded"
+		return result, nil
+	}
+
+	if len(filter.Tags) > 0 {
+		output, err := client.ListUserTags(ctx, &iam.ListUserTagsInput{UserName: aws.String(username)})
+		if err != nil {
+			return result, fmt.Errorf("failed to list tags: %w", err)
+		}
+		if !hasTags(output.Tags, filter.Tags) {
+			result.Detail = "tags do not match"
+			return result, nil
+		}
+	}
+
+	// A missing login profile means the user cannot sign in to the console
+	output, err := client.GetLoginProfile(ctx, &iam.GetLoginProfileInput{UserName: aws.String(username)})
+	if err != nil {
+		var noSuchEntity *types.NoSuchEntityException
+		if errors.As(err, &noSuchEntity) {
+			result.Detail = "no console access"
+			return result, nil
+		}
+		return result, fmt.Errorf("failed to get login profile: %w", err)
+	}
+	if output.LoginProfile.PasswordResetRequired {
+		result.Detail = "reset already required"
+		return result, nil
+	}
+
+	result.Result = ExpirePending
+	return result, nil
+}
+
+// hasTags reports whether tags include every wanted key and value
+func hasTags(tags []types.Tag, want map[string]string) bool {
+	have := map[string]string{}
+	for _, tag := range tags {
+		have[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	for key, value := range want {
+		if v, ok := have[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// applyExpiry sets PasswordResetRequired for every pending user in plan
+func applyExpiry(ctx context.Context, client expireAPI, plan ExpireReport, workers int) ExpireReport {
+	results, errs := executor.Map(ctx, plan, workers, func(ctx context.Context, user ExpireResult) (ExpireResult, error) {
+		if user.Result != ExpirePending {
+			return user, nil
+		}
+		_, err := client.UpdateLoginProfile(ctx, &iam.UpdateLoginProfileInput{
+			UserName:              aws.String(user.UserName),
+			PasswordResetRequired: aws.Bool(true),
+		})
+		if err != nil {
+			return user, fmt.Errorf("failed to update login profile: %w", err)
+		}
+		user.Result = ExpireDone
+		return user, nil
+	})
+
+	report := ExpireReport(results)
+	for i, err := range errs {
+		if err != nil {
+			report[i] = ExpireResult{UserName: plan[i].UserName, Result: ExpireFailed, Detail: err.Error()}
+		}
+	}
+	return report
+}
+
+// recordExpiry notes the expired users in the audit log
+func recordExpiry(ctx context.Context, client *iam.Client, report ExpireReport) error {
+	actor := "unknown"
+	if user, err := awssdk.GetCurrentUser(ctx, client); err == nil {
+		actor = *user.UserName
+	}
+
+	return audit.Record(audit.Event{
+		Actor:  actor,
+		Action: "password.expire",
+		Target: strings.Join(report.Users(ExpireDone), ","),
+		Details: map[string]string{
+			"expired": strconv.Itoa(report.Count(ExpireDone)),
+			"failed":  strconv.Itoa(report.Count(ExpireFailed)),
+		},
+	})
+}
+
+// expiryError fails the command when any user could not be processed
+func expiryError(report ExpireReport) error {
+	if failed := report.Count(ExpireFailed); failed > 0 {
+		return exitcode.New(exitcode.Failure, fmt.Errorf("❌ Expire failed for %d user(s)", failed))
+	}
+	return nil
+}
@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/audit"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/exitcode"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/output"
+	"github.com/yourusername/iamctl/internal/passwordpolicy"
+	"github.com/yourusername/iamctl/internal/prompt"
+)
+
+// policyView renders a password policy as SETTING/VALUE rows
+type policyView passwordpolicy.Policy
+
+// Table implements output.Tabular
+func (v policyView) Table() output.Table {
+	table := output.Table{Headers: []string{"SETTING", "VALUE"}}
+	for _, setting := range passwordpolicy.Policy(v).Settings() {
+		table.Rows = append(table.Rows, []string{setting.Name, setting.Value})
+	}
+	return table
+}
+
+// policyDiff renders the changes between two password policies
+type policyDiff []passwordpolicy.Change
+
+// Table implements output.Tabular
+func (d policyDiff) Table() output.Table {
+	table := output.Table{Headers: []string{"SETTING", "CURRENT", "DESIRED"}}
+	for _, change := range d {
+		table.Rows = append(table.Rows, []string{change.Setting, change.Current, change.Desired})
+	}
+	return table
+}
+
+// NewPolicyCommand creates the password policy command and its subcommands
+func NewPolicyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "View and manage the account password policy",
+	}
+
+	cmd.AddCommand(newPolicyShowCommand())
+	cmd.AddCommand(newPolicySetCommand())
+	cmd.AddCommand(newPolicyDiffCommand())
+
+	return cmd
+}
+
+func newPolicyShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the account password policy",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get profile from flags or environment
+			profile, _ := cmd.Flags().GetString("profile")
+			format, _ := cmd.Flags().GetString("output")
+
+			if err := output.ValidateFormat(format); err != nil {
+				return err
+			}
+
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			// Create IAM client
+			client, err := awssdk.NewIAMClient(profile)
+			if err != nil {
+				return handlePolicyErrors(err)
+			}
+
+			policy, err := passwordpolicy.Load(ctx, client)
+			if err != nil {
+				return handlePolicyErrors(err)
+			}
+
+			if policy.Source != passwordpolicy.SourceAccount {
+				fmt.Fprintf(os.Stderr, "No account password policy is set; iamctl checks passwords against its %s\n", policy.Describe())
+				return nil
+			}
+			return output.Render(os.Stdout, format, policyView(policy))
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().StringP("output", "o", "", "Output format (text, csv or json)")
+
+	return cmd
+}
+
+func newPolicySetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Update the account password policy",
+		Long: `Update the account password policy from a preset, a YAML file or the
+current policy, with individual settings overridden by flags. The changes are
+shown before a confirmation and a current MFA code are asked for; --dry-run
+stops after showing them.
+
+Presets: cis (CIS AWS Foundations Benchmark) and nist (NIST SP 800-63B
+inspired: long passwords without composition rules or forced expiry).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get profile from flags or environment
+			profile, _ := cmd.Flags().GetString("profile")
+			mfaSerial, _ := cmd.Flags().GetString("mfa-serial")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			// Read the current policy under its own deadline; the prompts
+			// below wait on a human
+			lookupCtx, cancelLookup := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelLookup()
+
+			// Create IAM client
+			client, err := awssdk.NewIAMClient(profile)
+			if err != nil {
+				return handlePolicyErrors(err)
+			}
+
+			// 1. Work out the desired policy
+			current, err := passwordpolicy.Load(lookupCtx, client)
+			if err != nil {
+				return handlePolicyErrors(err)
+			}
+			desired, err := desiredPolicy(cmd, current)
+			if err != nil {
+				return err
+			}
+
+			// 2. Show what would change
+			changes := passwordpolicy.Diff(current, desired)
+			if len(changes) == 0 {
+				fmt.Println("✅ Password policy already up to date")
+				return nil
+			}
+			if err := output.Render(os.Stdout, output.FormatText, policyDiff(changes)); err != nil {
+				return err
+			}
+			if dryRun {
+				fmt.Println("Dry run: password policy not changed")
+				return nil
+			}
+
+			// 3. Confirm and prove possession of a current MFA code
+			if err := prompt.Confirm("Apply these password policy changes?"); err != nil {
+				return fmt.Errorf("❌ Operation cancelled: %v", err)
+			}
+			session, err := awssdk.RequireMFA(context.Background(), profile, mfaSerial, mfatoken.ForProfile(profile, prompt.MFACode))
+			if err != nil {
+				return fmt.Errorf("❌ Policy update failed: Invalid credentials")
+			}
+
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			// 4. Apply with the verified session
+			if err := updatePasswordPolicy(ctx, session.IAMClient(), desired); err != nil {
+				return fmt.Errorf("❌ Policy update failed: %v", err)
+			}
+			if err := recordPolicyChange(ctx, session.IAMClient(), desired, changes); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write audit log: %v\n", err)
+			}
+
+			fmt.Println("✅ Password policy updated")
+			return nil
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().String("mfa-serial", "", "MFA device serial number (defaults to your only MFA device)")
+	cmd.Flags().Bool("dry-run", false, "Show the changes without applying them")
+	addPolicySourceFlags(cmd)
+	cmd.Flags().Int("min-length", 0, "Minimum password length")
+	cmd.Flags().Bool("require-uppercase", false, "Require an uppercase letter")
+	cmd.Flags().Bool("require-lowercase", false, "Require a lowercase letter")
+	cmd.Flags().Bool("require-numbers", false, "Require a number")
+	cmd.Flags().Bool("require-symbols", false, "Require a symbol")
+	cmd.Flags().Int("reuse-prevention", 0, "Number of previous passwords that cannot be reused (0 disables)")
+	cmd.Flags().Int("max-age", 0, "Days before a password expires (0 disables)")
+	cmd.Flags().Bool("allow-users-to-change", false, "Allow users to change their own password")
+	cmd.Flags().Bool("hard-expiry", false, "Require an administrator to reset expired passwords")
+
+	return cmd
+}
+
+func newPolicyDiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the account password policy with a preset or file",
+		Long: `Compare the account password policy with a preset or a YAML file.
+The command exits with status 2 when they differ, so it can gate CI pipelines.`,
+		// A difference is a CI result, not a usage mistake
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get profile from flags or environment
+			profile, _ := cmd.Flags().GetString("profile")
+			format, _ := cmd.Flags().GetString("output")
+
+			if err := output.ValidateFormat(format); err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("preset") && !cmd.Flags().Changed("file") {
+				return fmt.Errorf("one of --preset or --file is required")
+			}
+			desired, err := policyFromSourceFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			// Create IAM client
+			client, err := awssdk.NewIAMClient(profile)
+			if err != nil {
+				return handlePolicyErrors(err)
+			}
+
+			current, err := passwordpolicy.Load(ctx, client)
+			if err != nil {
+				return handlePolicyErrors(err)
+			}
+
+			changes := passwordpolicy.Diff(current, *desired)
+			if len(changes) == 0 {
+				fmt.Printf("✅ Password policy matches the %s\n", desired.Source)
+				return nil
+			}
+			if err := output.Render(os.Stdout, format, policyDiff(changes)); err != nil {
+				return err
+			}
+			return exitcode.New(exitcode.Violation, fmt.Errorf("❌ Password policy differs from the %s in %d setting(s)", desired.Source, len(changes)))
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().StringP("output", "o", "", "Output format (text, csv or json)")
+	addPolicySourceFlags(cmd)
+
+	return cmd
+}
+
+// addPolicySourceFlags adds the flags naming a preset or policy file
+func addPolicySourceFlags(cmd *cobra.Command) {
+	cmd.Flags().String("preset", "", "Start from a preset policy (cis or nist)")
+	cmd.Flags().String("file", "", "Start from a policy in a YAML file")
+	cmd.MarkFlagsMutuallyExclusive("preset", "file")
+}
+
+// policyFromSourceFlags returns the policy named by --preset or --file, or
+// nil when neither is given
+func policyFromSourceFlags(cmd *cobra.Command) (*passwordpolicy.Policy, error) {
+	preset, _ := cmd.Flags().GetString("preset")
+	file, _ := cmd.Flags().GetString("file")
+
+	var policy passwordpolicy.Policy
+	var err error
+	switch {
+	case preset != "":
+		policy, err = passwordpolicy.Preset(preset)
+	case file != "":
+		policy, err = passwordpolicy.LoadFile(file)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// desiredPolicy builds the policy to apply: the preset or file if given,
+// otherwise the current account policy, with any setting flags on top
+func desiredPolicy(cmd *cobra.Command, current passwordpolicy.Policy) (passwordpolicy.Policy, error) {
+	source, err := policyFromSourceFlags(cmd)
+	if err != nil {
+		return passwordpolicy.Policy{}, err
+	}
+
+	desired := current
+	switch {
+	case source != nil:
+		desired = *source
+	case current.Source != passwordpolicy.SourceAccount:
+		// Without an account policy, start from IAM's own defaults rather
+		// than iamctl's local fallback rules
+		desired = passwordpolicy.Policy{MinimumLength: 8, Source: "IAM default"}
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("min-length") {
+		desired.MinimumLength, _ = flags.GetInt("min-length")
+	}
+	if flags.Changed("require-uppercase") {
+		desired.RequireUppercase, _ = flags.GetBool("require-uppercase")
+	}
+	if flags.Changed("require-lowercase") {
+		desired.RequireLowercase, _ = flags.GetBool("require-lowercase")
+	}
+	if flags.Changed("require-numbers") {
+		desired.RequireNumbers, _ = flags.GetBool("require-numbers")
+	}
+	if flags.Changed("require-symbols") {
+		desired.RequireSymbols, _ = flags.GetBool("require-symbols")
+	}
+	if flags.Changed("reuse-prevention") {
+		desired.PasswordReusePrevention, _ = flags.GetInt("reuse-prevention")
+	}
+	if flags.Changed("max-age") {
+		desired.MaxPasswordAge, _ = flags.GetInt("max-age")
+	}
+	if flags.Changed("allow-users-to-change") {
+		desired.AllowUsersToChangePassword, _ = flags.GetBool("allow-users-to-change")
+	}
+	if flags.Changed("hard-expiry") {
+		desired.HardExpiry, _ = flags.GetBool("hard-expiry")
+	}
+
+	return desired, desired.Check()
+}
+
+// passwordPolicyAPI is the subset of the IAM client used to set the policy
+type passwordPolicyAPI interface {
+	UpdateAccountPasswordPolicy(context.Context, *iam.UpdateAccountPasswordPolicyInput, ...func(*iam.Options)) (*iam.UpdateAccountPasswordPolicyOutput, error)
+}
+
+// updatePasswordPolicy makes policy the account password policy
+func updatePasswordPolicy(ctx context.Context, client passwordPolicyAPI, policy passwordpolicy.Policy) error {
+	if err := policy.Check(); err != nil {
+		return err
+	}
+	if _, err := client.UpdateAccountPasswordPolicy(ctx, policy.UpdateInput()); err != nil {
+		return fmt.Errorf("failed to update account password policy: %w", err)
+	}
+	return nil
+}
+
+// recordPolicyChange notes the new password policy in the audit log
+func recordPolicyChange(ctx context.Context, client *iam.Client, policy passwordpolicy.Policy, changes []passwordpolicy.Change) error {
+	actor := "unknown"
+	if user, err := awssdk.GetCurrentUser(ctx, client); err == nil {
+		actor = *user.UserName
+	}
+
+	details := map[string]string{"source": policy.Source}
+	for _, change := range changes {
+		details[change.Setting] = change.Current + " -> " + change.Desired
+	}
+
+	return audit.Record(audit.Event{
+		Actor:   actor,
+		Action:  "password.policy.set",
+		Target:  "account",
+		Details: details,
+	})
+}
+
+// handlePolicyErrors converts SDK errors to user-friendly messages
+func handlePolicyErrors(err error) error {
+	return fmt.Errorf("❌ Password policy: %v", err)
+}
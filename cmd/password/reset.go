@@ -2,16 +2,21 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/passwordpolicy"
+	"github.com/yourusername/iamctl/internal/prompt"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 )
 
 // NewResetCommand creates the password reset command
@@ -19,16 +24,38 @@ func NewResetCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "reset",
 		Short: "Reset IAM user password",
-		Long:  `Reset IAM user password with MFA verification.`,
+		Long: `Reset IAM user password with MFA verification.
+
+With --generate a random password meeting the account password policy is set
+instead of prompting, and the user must change it at next sign-in. It is
+never printed unless --show is given; deliver it with --output-file (written
+with mode 0600) or --secret-name (stored in AWS Secrets Manager).`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Get profile from flags or environment
 			profile, _ := cmd.Flags().GetString("profile")
 			username, _ := cmd.Flags().GetString("username")
 			serialNumber, _ := cmd.Flags().GetString("mfa-serial")
+			generate, _ := cmd.Flags().GetBool("generate")
+			length, _ := cmd.Flags().GetInt("length")
+			excludeAmbiguous, _ := cmd.Flags().GetBool("exclude-ambiguous")
+			delivery := deliveryFromFlags(cmd)
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
+			if err := checkGenerateFlags(cmd, generate, delivery); err != nil {
+				return err
+			}
+			breaches, err := breachCheckFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			minStrength, err := strengthCheckFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Look up the user under its own deadline; the prompts below wait
+			// on a human
+			lookupCtx, cancelLookup := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelLookup()
 
 			// Create IAM client
 			client, err := awssdk.NewIAMClient(profile)
@@ -38,28 +65,45 @@ func NewResetCommand() *cobra.Command {
 
 			// Get current user if username not provided
 			if username == "" {
-				user, err := awssdk.GetCurrentUser(ctx, client)
+				user, err := awssdk.GetCurrentUser(lookupCtx, client)
 				if err != nil {
 					return handlePasswordResetErrors(err)
 				}
 				username = *user.UserName
 			}
 
-			// Get MFA token first (security critical order)
-			mfaToken, err := getMFAToken()
+			// Validate MFA first (security critical order)
+			session, err := awssdk.RequireMFA(context.Background(), profile, serialNumber, mfatoken.ForProfile(profile, prompt.MFACode))
 			if err != nil {
-				return fmt.Errorf("❌ Reset failed: failed to read MFA token")
+				return fmt.Errorf("❌ Reset failed: Invalid credentials")
 			}
 
-			// Validate MFA first (security critical)
-			if err := validateMFA(ctx, client, profile, username, serialNumber, mfaToken); err != nil {
-				return fmt.Errorf("❌ Reset failed: Invalid credentials")
+			// Read the password securely after MFA validation, before the
+			// API deadline starts
+			var password string
+			if !generate {
+				password, err = prompt.NewPassword("Enter new password")
+				if err != nil {
+					return fmt.Errorf("❌ Reset failed: failed to read password: %v", err)
+				}
 			}
 
-			// Get password securely after MFA validation
-			password, err := getPassword()
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			// Validate against the account password policy
+			policy, err := passwordpolicy.Load(ctx, session.IAMClient())
 			if err != nil {
-				return fmt.Errorf("❌ Reset failed: failed to read password")
+				return fmt.Errorf("❌ Reset failed: %v", err)
+			}
+
+			// Generate a password that meets the policy
+			if generate {
+				password, err = policy.Generate(length, excludeAmbiguous)
+				if err != nil {
+					return fmt.Errorf("❌ Reset failed: failed to generate password: %v", err)
+				}
 			}
 
 			// Clear password from memory when done
@@ -70,17 +114,47 @@ func NewResetCommand() *cobra.Command {
 				}
 			}()
 
-			// Validate password complexity
-			if !isValidPassword(password) {
-				return fmt.Errorf("❌ Reset failed: password does not meet complexity requirements (14+ chars, 3/4 character types)")
+			if err := checkPassword(policy, password); err != nil {
+				return fmt.Errorf("❌ Reset failed: %v", err)
+			}
+			if err := breaches.Check(password); err != nil {
+				return fmt.Errorf("❌ Reset failed: %v", err)
+			}
+			if err := minStrength.Check(ctx, session.IAMClient(), password, username); err != nil {
+				return fmt.Errorf("❌ Reset failed: %v", err)
+			}
+			for _, notice := range policy.Notices() {
+				fmt.Fprintf(os.Stderr, "Note: %s\n", notice)
 			}
 
-			// Reset password
-			err = resetPassword(ctx, client, profile, username, password, serialNumber, mfaToken)
+			// Hand a generated password over before setting it, so it is
+			// never set without anyone knowing it
+			undo := func() {}
+			if generate {
+				undo, err = delivery.deliver(ctx, secretsmanager.NewFromConfig(session.Config), username, password)
+				if err != nil {
+					return fmt.Errorf("❌ Reset failed: %v", err)
+				}
+			}
+
+			// Reset password with the verified session
+			err = resetPassword(ctx, session.IAMClient(), username, password, generate)
 			if err != nil {
+				undo()
+				var violation *types.PasswordPolicyViolationException
+				if errors.As(err, &violation) {
+					return fmt.Errorf("❌ Reset failed: IAM rejected the password under the %s (it may match a recent password)", policy.Describe())
+				}
 				return fmt.Errorf("❌ Reset failed: Invalid credentials")
 			}
 
+			if delivery.Show {
+				fmt.Printf("Generated password for %s: %s\n", username, password)
+			}
+			if generate {
+				fmt.Printf("✅ Password reset complete; %s must choose a new password at next sign-in\n", username)
+				return nil
+			}
 			fmt.Println("✅ Password reset complete")
 			return nil
 		},
@@ -89,44 +163,45 @@ func NewResetCommand() *cobra.Command {
 	// Add flags
 	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
 	cmd.Flags().String("username", "", "Username to reset password for (defaults to current user)")
-	cmd.Flags().String("mfa-serial", "", "MFA device serial number (required)")
+	cmd.Flags().String("mfa-serial", "", "MFA device serial number (defaults to your only MFA device)")
+	cmd.Flags().Bool("generate", false, "Generate a random password that meets the account policy")
+	cmd.Flags().Int("length", 0, fmt.Sprintf("Length of the generated password (default %d or the policy minimum)", passwordpolicy.DefaultGeneratedLength))
+	cmd.Flags().Bool("exclude-ambiguous", false, "Leave easily confused characters out of the generated password ("+passwordpolicy.Ambiguous+")")
+	addDeliveryFlags(cmd)
+	addBreachFlags(cmd)
+	addStrengthFlags(cmd)
 
 	return cmd
 }
 
-// validateMFA validates the MFA token before proceeding with password reset
-func validateMFA(ctx context.Context, client *iam.Client, profile, username, serialNumber, mfaToken string) error {
-	// Create MFA-enabled client to validate MFA token
-	_, err := awssdk.GetMFAEnabledClient(ctx, profile, "", serialNumber, mfaToken)
-	if err != nil {
-		return fmt.Errorf("MFA validation failed: %w", err)
-	}
-	return nil
+// loginProfileAPI is the subset of the IAM client used to set passwords
+type loginProfileAPI interface {
+	UpdateLoginProfile(context.Context, *iam.UpdateLoginProfileInput, ...func(*iam.Options)) (*iam.UpdateLoginProfileOutput, error)
+	CreateLoginProfile(context.Context, *iam.CreateLoginProfileInput, ...func(*iam.Options)) (*iam.CreateLoginProfileOutput, error)
 }
 
-// resetPassword resets the user's password with MFA verification
-func resetPassword(ctx context.Context, client *iam.Client, profile, username, password, serialNumber, mfaToken string) error {
-	// Create MFA-enabled client
-	mfaClient, err := awssdk.GetMFAEnabledClient(ctx, profile, "", serialNumber, mfaToken)
-	if err != nil {
-		return fmt.Errorf("failed to create MFA-enabled client: %w", err)
-	}
-
+// resetPassword sets the user's password, creating a login profile if
+// needed. With requireReset the user must change it at next sign-in.
+func resetPassword(ctx context.Context, client loginProfileAPI, username, password string, requireReset bool) error {
 	// Reset password
 	input := &iam.UpdateLoginProfileInput{
 		UserName: aws.String(username),
 		Password: aws.String(password),
 	}
+	if requireReset {
+		input.PasswordResetRequired = aws.Bool(true)
+	}
 
-	_, err = mfaClient.UpdateLoginProfile(ctx, input)
+	_, err := client.UpdateLoginProfile(ctx, input)
 	if err != nil {
 		// If login profile doesn't exist, create it
 		if strings.Contains(err.Error(), "NoSuchEntity") {
 			createInput := &iam.CreateLoginProfileInput{
-				UserName: aws.String(username),
-				Password: aws.String(password),
+				UserName:              aws.String(username),
+				Password:              aws.String(password),
+				PasswordResetRequired: requireReset,
 			}
-			_, err = mfaClient.CreateLoginProfile(ctx, createInput)
+			_, err = client.CreateLoginProfile(ctx, createInput)
 			if err != nil {
 				return fmt.Errorf("failed to create login profile: %w", err)
 			}
@@ -138,75 +213,19 @@ func resetPassword(ctx context.Context, client *iam.Client, profile, username, p
 	return nil
 }
 
-// getPassword securely reads a password from the terminal
-func getPassword() (string, error) {
-	fmt.Print("Enter new password: ")
-	password, err := term.ReadPassword(int(syscall.Stdin))
-	fmt.Println() // Add a newline after password input
-	if err != nil {
-		return "", err
-	}
-
-	fmt.Print("Confirm new password: ")
-	confirm, err := term.ReadPassword(int(syscall.Stdin))
-	fmt.Println() // Add a newline after password input
-	if err != nil {
-		return "", err
-	}
-
-	if string(password) != string(confirm) {
-		return "", fmt.Errorf("passwords do not match")
-	}
-
-	return string(password), nil
-}
-
-// getMFAToken securely reads an MFA token from the terminal
-func getMFAToken() (string, error) {
-	fmt.Print("Enter MFA token: ")
-	token, err := term.ReadPassword(int(syscall.Stdin))
-	fmt.Println() // Add a newline after token input
-	if err != nil {
-		return "", err
+// checkPassword reports every rule of the policy the password does not meet
+func checkPassword(policy passwordpolicy.Policy, password string) error {
+	violations := policy.Validate(password)
+	if len(violations) == 0 {
+		return nil
 	}
-	return string(token), nil
-}
 
-// isValidPassword checks password complexity requirements
-func isValidPassword(password string) bool {
-	if len(password) < 14 {
-		return false
+	var b strings.Builder
+	fmt.Fprintf(&b, "password does not meet the %s", policy.Describe())
+	for _, violation := range violations {
+		fmt.Fprintf(&b, "\n  - %s", violation)
 	}
-
-	var hasLower, hasUpper, hasDigit, hasSpecial bool
-	for _, char := range password {
-		switch {
-		case char >= 'a' && char <= 'z':
-			hasLower = true
-		case char >= 'A' && char <= 'Z':
-			hasUpper = true
-		case char >= '0' && char <= '9':
-			hasDigit = true
-		default:
-			hasSpecial = true
-		}
-	}
-
-	charTypes := 0
-	if hasLower {
-		charTypes++
-	}
-	if hasUpper {
-		charTypes++
-	}
-	if hasDigit {
-		charTypes++
-	}
-	if hasSpecial {
-		charTypes++
-	}
-
-	return charTypes >= 3
+	return errors.New(b.String())
 }
 
 // handlePasswordResetErrors converts SDK errors to user-friendly messages with unified error messaging
@@ -3,12 +3,21 @@ package cmd
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/smithy-go"
 	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/exitcode"
+	"github.com/yourusername/iamctl/internal/passwordpolicy"
 )
 
 // Mock IAM client for testing
@@ -17,14 +26,14 @@ type mockPasswordIAMClient struct {
 	createLoginProfileFunc func(context.Context, *iam.CreateLoginProfileInput) (*iam.CreateLoginProfileOutput, error)
 }
 
-func (m *mockPasswordIAMClient) UpdateLoginProfile(ctx context.Context, input *iam.UpdateLoginProfileInput) (*iam.UpdateLoginProfileOutput, error) {
+func (m *mockPasswordIAMClient) UpdateLoginProfile(ctx context.Context, input *iam.UpdateLoginProfileInput, optFns ...func(*iam.Options)) (*iam.UpdateLoginProfileOutput, error) {
 	if m.updateLoginProfileFunc != nil {
 		return m.updateLoginProfileFunc(ctx, input)
 	}
 	return &iam.UpdateLoginProfileOutput{}, nil
 }
 
-func (m *mockPasswordIAMClient) CreateLoginProfile(ctx context.Context, input *iam.CreateLoginProfileInput) (*iam.CreateLoginProfileOutput, error) {
+func (m *mockPasswordIAMClient) CreateLoginProfile(ctx context.Context, input *iam.CreateLoginProfileInput, optFns ...func(*iam.Options)) (*iam.CreateLoginProfileOutput, error) {
 	if m.createLoginProfileFunc != nil {
 		return m.createLoginProfileFunc(ctx, input)
 	}
@@ -41,74 +50,660 @@ func TestSuccessfulResetWithValidMFA(t *testing.T) {
 
 	// Test successful reset
 	ctx := context.Background()
-	err := resetPasswordWithClient(ctx, client, "test-profile", "testuser", "ValidPass123!", "arn:aws:iam::123456789012:mfa/testuser", "123456")
+	err := resetPassword(ctx, client, "testuser", "ValidPass123!", false)
 	if err != nil {
 		t.Errorf("Expected successful reset, got error: %v", err)
 	}
 }
 
 func TestInvalidPasswordComplexity(t *testing.T) {
+	// The fallback policy applies when the account has none
+	policy := passwordpolicy.Default()
+
 	// Test password too short
-	if isValidPassword("Short1!") {
+	if checkPassword(policy, "Short1!") == nil {
 		t.Error("Expected short password to fail complexity check")
 	}
 
 	// Test password with only 2 character types
-	if isValidPassword("onlylowercaseandnumbers123") {
+	if checkPassword(policy, "onlylowercaseandnumbers123") == nil {
 		t.Error("Expected password with only 2 character types to fail complexity check")
 	}
 
 	// Test valid password
-	if !isValidPassword("ValidPass123!@") { // 15 characters with 4 types
-		t.Error("Expected valid password to pass complexity check")
+	if err := checkPassword(policy, "ValidPass123!@"); err != nil { // 15 characters with 4 types
+		t.Errorf("Expected valid password to pass complexity check, got: %v", err)
 	}
 }
 
-func TestMFAValidationFailure(t *testing.T) {
-	// Setup mock client that returns MFA error
+func TestCheckPasswordReportsEveryRule(t *testing.T) {
+	policy := passwordpolicy.Policy{
+		MinimumLength:    16,
+		RequireUppercase: true,
+		RequireNumbers:   true,
+		RequireSymbols:   true,
+		Source:           passwordpolicy.SourceAccount,
+	}
+
+	err := checkPassword(policy, "lowercase only")
+	if err == nil {
+		t.Fatal("Expected password to fail the account policy")
+	}
+	for _, rule := range []string{"minimum-length", "require-uppercase", "require-numbers", "require-symbols"} {
+		if !strings.Contains(err.Error(), rule) {
+			t.Errorf("Expected %s in error, got: %v", rule, err)
+		}
+	}
+}
+
+func TestResetPasswordAccessDenied(t *testing.T) {
+	// Setup mock client that denies the login profile update
 	client := &mockPasswordIAMClient{
 		updateLoginProfileFunc: func(ctx context.Context, input *iam.UpdateLoginProfileInput) (*iam.UpdateLoginProfileOutput, error) {
 			return nil, &awssdk.PermissionError{Err: errors.New("access denied")}
 		},
 	}
 
-	// Test reset failure due to MFA validation
+	// Test that the denial is returned rather than reported as success
 	ctx := context.Background()
-	err := resetPasswordWithClient(ctx, client, "test-profile", "testuser", "ValidPass123!", "arn:aws:iam::123456789012:mfa/testuser", "invalid")
+	err := resetPassword(ctx, client, "testuser", "ValidPass123!", false)
 	if err == nil {
-		t.Error("Expected reset to fail due to MFA validation error")
+		t.Fatal("Expected reset to fail when UpdateLoginProfile is denied")
 	}
 
-	// Check that the error is properly classified
+	// Check that the underlying error is preserved
 	if !strings.Contains(err.Error(), "access denied") {
 		t.Errorf("Expected error to contain access denied message, got: %v", err)
 	}
 }
 
-// resetPasswordWithClient is a testable version of resetPassword that accepts a mock client
-func resetPasswordWithClient(ctx context.Context, client *mockPasswordIAMClient, profile, username, password, serialNumber, mfaToken string) error {
-	// Reset password
-	input := &iam.UpdateLoginProfileInput{
-		UserName: aws.String(username),
-		Password: aws.String(password),
+type mockPasswordPolicyClient struct {
+	input *iam.UpdateAccountPasswordPolicyInput
+}
+
+func (m *mockPasswordPolicyClient) UpdateAccountPasswordPolicy(ctx context.Context, input *iam.UpdateAccountPasswordPolicyInput, optFns ...func(*iam.Options)) (*iam.UpdateAccountPasswordPolicyOutput, error) {
+	m.input = input
+	return &iam.UpdateAccountPasswordPolicyOutput{}, nil
+}
+
+func TestDesiredPolicy(t *testing.T) {
+	current := passwordpolicy.Policy{MinimumLength: 10, RequireNumbers: true, Source: passwordpolicy.SourceAccount}
+
+	// Flags override the current policy
+	cmd := newPolicySetCommand()
+	if err := cmd.ParseFlags([]string{"--min-length", "16", "--require-numbers=false"}); err != nil {
+		t.Fatal(err)
+	}
+	desired, err := desiredPolicy(cmd, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desired.MinimumLength != 16 || desired.RequireNumbers {
+		t.Errorf("desired = %+v", desired)
+	}
+
+	// Flags override a preset
+	cmd = newPolicySetCommand()
+	if err := cmd.ParseFlags([]string{"--preset", "cis", "--max-age", "0"}); err != nil {
+		t.Fatal(err)
+	}
+	desired, err = desiredPolicy(cmd, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desired.MinimumLength != 14 || !desired.RequireSymbols || desired.MaxPasswordAge != 0 {
+		t.Errorf("desired = %+v", desired)
+	}
+
+	// Settings IAM would reject are refused
+	cmd = newPolicySetCommand()
+	if err := cmd.ParseFlags([]string{"--min-length", "200"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := desiredPolicy(cmd, current); err == nil {
+		t.Error("Expected an out of range minimum length to fail")
+	}
+}
+
+func TestUpdatePasswordPolicy(t *testing.T) {
+	client := &mockPasswordPolicyClient{}
+	policy, _ := passwordpolicy.Preset("cis")
+
+	if err := updatePasswordPolicy(context.Background(), client, policy); err != nil {
+		t.Fatal(err)
+	}
+	if client.input == nil || *client.input.PasswordReusePrevention != 24 || !client.input.RequireSymbols {
+		t.Errorf("Unexpected update input: %+v", client.input)
+	}
+}
+
+type mockChangePasswordClient struct {
+	changePasswordFunc func(context.Context, *iam.ChangePasswordInput) (*iam.ChangePasswordOutput, error)
+}
+
+func (m *mockChangePasswordClient) ChangePassword(ctx context.Context, input *iam.ChangePasswordInput, optFns ...func(*iam.Options)) (*iam.ChangePasswordOutput, error) {
+	return m.changePasswordFunc(ctx, input)
+}
+
+func TestChangePassword(t *testing.T) {
+	var got *iam.ChangePasswordInput
+	client := &mockChangePasswordClient{
+		changePasswordFunc: func(ctx context.Context, input *iam.ChangePasswordInput) (*iam.ChangePasswordOutput, error) {
+			got = input
+			return &iam.ChangePasswordOutput{}, nil
+		},
+	}
+
+	if err := changePassword(context.Background(), client, "OldPass123!@#", "NewPass123!@#"); err != nil {
+		t.Fatal(err)
+	}
+	if *got.OldPassword != "OldPass123!@#" || *got.NewPassword != "NewPass123!@#" {
+		t.Errorf("Unexpected ChangePassword input: %+v", got)
+	}
+}
+
+func TestPasswordChangeErrorGuidance(t *testing.T) {
+	policy := passwordpolicy.Default()
+	expiring := policy
+	expiring.HardExpiry = true
+	tests := []struct {
+		err      error
+		policy   passwordpolicy.Policy
+		required bool
+		want     string
+	}{
+		{&types.EntityTemporarilyUnmodifiableException{Message: aws.String("busy")}, policy, false, "try again"},
+		{&types.PasswordPolicyViolationException{Message: aws.String("reused")}, policy, false, "recent password"},
+		{&types.InvalidUserTypeException{Message: aws.String("root")}, policy, false, "only IAM users"},
+		{&smithy.GenericAPIError{Code: "AccessDenied"}, expiring, true, "iamctl password reset"},
+		{&smithy.GenericAPIError{Code: "AccessDenied"}, policy, true, "Invalid credentials"},
+		{&smithy.GenericAPIError{Code: "AccessDenied"}, policy, false, "Invalid credentials"},
+	}
+
+	for _, tt := range tests {
+		client := &mockChangePasswordClient{
+			changePasswordFunc: func(ctx context.Context, input *iam.ChangePasswordInput) (*iam.ChangePasswordOutput, error) {
+				return nil, tt.err
+			},
+		}
+		err := changePassword(context.Background(), client, "OldPass123!@#", "NewPass123!@#")
+		if got := handlePasswordChangeErrors(err, tt.policy, tt.required); !strings.Contains(got.Error(), tt.want) {
+			t.Errorf("handlePasswordChangeErrors(%T, required=%v) = %v, want %q", tt.err, tt.required, got, tt.want)
+		}
+	}
+}
+
+type mockGetLoginProfileClient struct {
+	output *iam.GetLoginProfileOutput
+	err    error
+}
+
+func (m *mockGetLoginProfileClient) GetLoginProfile(ctx context.Context, input *iam.GetLoginProfileInput, optFns ...func(*iam.Options)) (*iam.GetLoginProfileOutput, error) {
+	return m.output, m.err
+}
+
+func TestResetRequired(t *testing.T) {
+	ctx := context.Background()
+	required := &mockGetLoginProfileClient{output: &iam.GetLoginProfileOutput{
+		LoginProfile: &types.LoginProfile{UserName: aws.String("alice"), PasswordResetRequired: true},
+	}}
+
+	if !resetRequired(ctx, required, "alice") {
+		t.Error("expected the login profile's reset flag to be reported")
+	}
+	if resetRequired(ctx, required, "") {
+		t.Error("expected no answer without a user name")
+	}
+	if resetRequired(ctx, &mockGetLoginProfileClient{err: errors.New("access denied")}, "alice") {
+		t.Error("expected an unreadable login profile to count as not required")
+	}
+}
+
+type mockSecretsClient struct {
+	created map[string]string
+	deleted []string
+	err     error
+}
+
+func (m *mockSecretsClient) CreateSecret(ctx context.Context, input *secretsmanager.CreateSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.CreateSecretOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.created == nil {
+		m.created = map[string]string{}
+	}
+	m.created[*input.Name] = *input.SecretString
+	return &secretsmanager.CreateSecretOutput{}, nil
+}
+
+func (m *mockSecretsClient) DeleteSecret(ctx context.Context, input *secretsmanager.DeleteSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.DeleteSecretOutput, error) {
+	m.deleted = append(m.deleted, *input.SecretId)
+	return &secretsmanager.DeleteSecretOutput{}, nil
+}
+
+func TestGeneratedPasswordRequiresReset(t *testing.T) {
+	var got *iam.UpdateLoginProfileInput
+	client := &mockPasswordIAMClient{
+		updateLoginProfileFunc: func(ctx context.Context, input *iam.UpdateLoginProfileInput) (*iam.UpdateLoginProfileOutput, error) {
+			got = input
+			return &iam.UpdateLoginProfileOutput{}, nil
+		},
+	}
+
+	if err := resetPassword(context.Background(), client, "alice", "ValidPass123!@", true); err != nil {
+		t.Fatal(err)
+	}
+	if got.PasswordResetRequired == nil || !*got.PasswordResetRequired {
+		t.Errorf("Expected PasswordResetRequired, got %+v", got)
+	}
+
+	// A typed password leaves an existing reset requirement alone
+	if err := resetPassword(context.Background(), client, "alice", "ValidPass123!@", false); err != nil {
+		t.Fatal(err)
+	}
+	if got.PasswordResetRequired != nil {
+		t.Errorf("Expected PasswordResetRequired unset, got %v", *got.PasswordResetRequired)
+	}
+}
+
+func TestDeliverGeneratedPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alice.txt")
+	secrets := &mockSecretsClient{}
+	delivery := passwordDelivery{OutputFile: path, SecretName: "iamctl/alice"}
+
+	undo, err := delivery.deliver(context.Background(), secrets, "alice", "ValidPass123!@")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("password file mode = %v, want 0600", info.Mode().Perm())
+	}
+	if !strings.Contains(secrets.created["iamctl/alice"], "ValidPass123!@") {
+		t.Errorf("secret = %q", secrets.created["iamctl/alice"])
+	}
+
+	// An existing file is never overwritten
+	if _, err := delivery.deliver(context.Background(), secrets, "alice", "Other"); err == nil {
+		t.Error("Expected delivery to an existing file to fail")
+	}
+
+	// A failed reset removes what was delivered
+	undo()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected password file to be removed, got %v", err)
+	}
+	if len(secrets.deleted) != 1 || secrets.deleted[0] != "iamctl/alice" {
+		t.Errorf("deleted secrets = %v", secrets.deleted)
+	}
+}
+
+func TestDeliveryCleansUpOnSecretFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alice.txt")
+	secrets := &mockSecretsClient{err: errors.New("ResourceExistsException")}
+	delivery := passwordDelivery{OutputFile: path, SecretName: "iamctl/alice"}
+
+	if _, err := delivery.deliver(context.Background(), secrets, "alice", "ValidPass123!@"); err == nil {
+		t.Fatal("Expected delivery to fail")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected password file to be removed, got %v", err)
+	}
+}
+
+type mockConsoleClient struct {
+	users         []types.User
+	loginProfiles map[string]types.LoginProfile
+}
+
+func (m *mockConsoleClient) ListUsers(ctx context.Context, input *iam.ListUsersInput, optFns ...func(*iam.Options)) (*iam.ListUsersOutput, error) {
+	return &iam.ListUsersOutput{Users: m.users}, nil
+}
+
+func (m *mockConsoleClient) GetUser(ctx context.Context, input *iam.GetUserInput, optFns ...func(*iam.Options)) (*iam.GetUserOutput, error) {
+	for _, user := range m.users {
+		if *user.UserName == aws.ToString(input.UserName) {
+			return &iam.GetUserOutput{User: &user}, nil
+		}
+	}
+	return nil, &types.NoSuchEntityException{Message: aws.String("no such user")}
+}
+
+func (m *mockConsoleClient) GetLoginProfile(ctx context.Context, input *iam.GetLoginProfileInput, optFns ...func(*iam.Options)) (*iam.GetLoginProfileOutput, error) {
+	profile, ok := m.loginProfiles[*input.UserName]
+	if !ok {
+		return nil, &types.NoSuchEntityException{Message: aws.String("no login profile")}
+	}
+	return &iam.GetLoginProfileOutput{LoginProfile: &profile}, nil
+}
+
+func TestConsoleAccessReport(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
 	}
 
-	_, err := client.UpdateLoginProfile(ctx, input)
+	client := &mockConsoleClient{
+		users: []types.User{
+			{UserName: aws.String("carol")},
+			{UserName: aws.String("alice"), PasswordLastUsed: daysAgo(3)},
+			{UserName: aws.String("bob"), PasswordLastUsed: daysAgo(200)},
+			{UserName: aws.String("dave")},
+		},
+		loginProfiles: map[string]types.LoginProfile{
+			"alice": {CreateDate: daysAgo(400)},
+			"bob":   {CreateDate: daysAgo(400)},
+			"dave":  {CreateDate: daysAgo(120), PasswordResetRequired: true},
+		},
+	}
+
+	report, err := buildConsoleAccessReport(context.Background(), client, 2, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, user := range report {
+		names = append(names, user.UserName)
+	}
+	if strings.Join(names, ",") != "alice,bob,carol,dave" {
+		t.Errorf("report order = %v", names)
+	}
+
+	carol := report[2]
+	if carol.LoginProfile || carol.UnusedDays != nil {
+		t.Errorf("carol = %+v, want no console access", carol)
+	}
+	dave := report[3]
+	if !dave.ResetRequired || *dave.UnusedDays != 120 {
+		t.Errorf("dave = %+v, want unused since creation with reset required", dave)
+	}
+
+	// Passwords never used count from the login profile's creation
+	stale := report.UnusedFor(90)
+	if len(stale) != 2 || stale[0].UserName != "bob" || stale[1].UserName != "dave" {
+		t.Errorf("UnusedFor(90) = %+v", stale)
+	}
+}
+
+func TestLookupUser(t *testing.T) {
+	client := &mockConsoleClient{users: []types.User{{UserName: aws.String("alice")}}}
+
+	user, err := lookupUser(context.Background(), client, "alice")
+	if err != nil || *user.UserName != "alice" {
+		t.Errorf("lookupUser() = %v, %v", user, err)
+	}
+	if _, err := lookupUser(context.Background(), client, "mallory"); err == nil {
+		t.Error("Expected an unknown user to fail")
+	}
+}
+
+type mockDeleteLoginProfileClient struct {
+	deleted []string
+}
+
+func (m *mockDeleteLoginProfileClient) DeleteLoginProfile(ctx context.Context, input *iam.DeleteLoginProfileInput, optFns ...func(*iam.Options)) (*iam.DeleteLoginProfileOutput, error) {
+	m.deleted = append(m.deleted, *input.UserName)
+	return &iam.DeleteLoginProfileOutput{}, nil
+}
+
+func TestDisableConsoleAccess(t *testing.T) {
+	client := &mockDeleteLoginProfileClient{}
+	if err := disableConsoleAccess(context.Background(), client, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.deleted) != 1 || client.deleted[0] != "bob" {
+		t.Errorf("deleted = %v", client.deleted)
+	}
+}
+
+func TestBreachCheck(t *testing.T) {
+	t.Setenv("IAMCTL_HOME", t.TempDir())
+
+	// "password" is in every breach list
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	list := "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:10434004\r\n"
+	if err := os.WriteFile(path, []byte(list), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Off unless a list is configured
+	cmd := NewResetCommand()
+	check, err := breachCheckFromFlags(cmd)
+	if err != nil || check.Check("password") != nil {
+		t.Errorf("Expected no breach check by default, got %+v, %v", check, err)
+	}
+
+	cmd = NewResetCommand()
+	if err := cmd.ParseFlags([]string{"--hibp-file", path}); err != nil {
+		t.Fatal(err)
+	}
+	check, err = breachCheckFromFlags(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := check.Check("password"); err == nil || !strings.Contains(err.Error(), "10434004") {
+		t.Errorf("Expected a breached password to be rejected, got %v", err)
+	}
+	if err := check.Check("ValidPass123!@"); err != nil {
+		t.Errorf("Expected an unlisted password to pass, got %v", err)
+	}
+
+	// Passwords seen less often than the minimum count are allowed
+	check.MinCount = 20000000
+	if err := check.Check("password"); err != nil {
+		t.Errorf("Expected a password below the minimum count to pass, got %v", err)
+	}
+
+	// A missing list fails before any prompt
+	cmd = NewChangeCommand()
+	if err := cmd.ParseFlags([]string{"--hibp-file", path + ".missing"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := breachCheckFromFlags(cmd); err == nil {
+		t.Error("Expected a missing list to fail")
+	}
+}
+
+type mockAliasClient struct {
+	aliases []string
+}
+
+func (m *mockAliasClient) ListAccountAliases(ctx context.Context, input *iam.ListAccountAliasesInput, optFns ...func(*iam.Options)) (*iam.ListAccountAliasesOutput, error) {
+	return &iam.ListAccountAliasesOutput{AccountAliases: m.aliases}, nil
+}
+
+func TestStrengthCheck(t *testing.T) {
+	t.Setenv("IAMCTL_HOME", t.TempDir())
+	ctx := context.Background()
+	client := &mockAliasClient{aliases: []string{"acme-prod"}}
+
+	// Off by default
+	check, err := strengthCheckFromFlags(NewResetCommand())
+	if err != nil || check.Check(ctx, client, "Password12345!", "alice") != nil {
+		t.Errorf("Expected no strength check by default, got %+v, %v", check, err)
+	}
+
+	cmd := NewChangeCommand()
+	if err := cmd.ParseFlags([]string{"--min-score", "3"}); err != nil {
+		t.Fatal(err)
+	}
+	check, err = strengthCheckFromFlags(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Meets the fallback policy but is trivially guessable
+	if err := passwordpolicy.Default().Validate("Password12345!"); len(err) != 0 {
+		t.Fatalf("Expected the fallback policy to accept the password, got %v", err)
+	}
+	if err := check.Check(ctx, client, "Password12345!", "alice"); err == nil || !strings.Contains(err.Error(), "password") {
+		t.Errorf("Expected a weak password to be rejected, got %v", err)
+	}
+
+	// The account alias is discounted
+	if err := check.Check(ctx, client, "acme-prod-alice!", "alice"); err == nil || !strings.Contains(err.Error(), "account alias") {
+		t.Errorf("Expected the account alias to be reported, got %v", err)
+	}
+
+	if err := check.Check(ctx, client, "h7#Kq2!vRz$9pLw&", "alice"); err != nil {
+		t.Errorf("Expected a strong password to pass, got %v", err)
+	}
+
+	cmd = NewResetCommand()
+	if err := cmd.ParseFlags([]string{"--min-score", "5"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strengthCheckFromFlags(cmd); err == nil {
+		t.Error("Expected an out of range score to fail")
+	}
+}
+
+type mockExpireClient struct {
+	mu            sync.Mutex
+	users         []string
+	groups        map[string][]string
+	tags          map[string]map[string]string
+	loginProfiles map[string]bool // value is PasswordResetRequired
+	updateErr     map[string]error
+	updated       []string
+}
+
+func (m *mockExpireClient) ListUsers(ctx context.Context, input *iam.ListUsersInput, optFns ...func(*iam.Options)) (*iam.ListUsersOutput, error) {
+	var users []types.User
+	for _, name := range m.users {
+		users = append(users, types.User{UserName: aws.String(name)})
+	}
+	return &iam.ListUsersOutput{Users: users}, nil
+}
+
+func (m *mockExpireClient) GetGroup(ctx context.Context, input *iam.GetGroupInput, optFns ...func(*iam.Options)) (*iam.GetGroupOutput, error) {
+	members, ok := m.groups[*input.GroupName]
+	if !ok {
+		return nil, &types.NoSuchEntityException{Message: aws.String("no such group")}
+	}
+	var users []types.User
+	for _, name := range members {
+		users = append(users, types.User{UserName: aws.String(name)})
+	}
+	return &iam.GetGroupOutput{Users: users}, nil
+}
+
+func (m *mockExpireClient) ListUserTags(ctx context.Context, input *iam.ListUserTagsInput, optFns ...func(*iam.Options)) (*iam.ListUserTagsOutput, error) {
+	var tags []types.Tag
+	for key, value := range m.tags[*input.UserName] {
+		tags = append(tags, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	return &iam.ListUserTagsOutput{Tags: tags}, nil
+}
+
+func (m *mockExpireClient) GetLoginProfile(ctx context.Context, input *iam.GetLoginProfileInput, optFns ...func(*iam.Options)) (*iam.GetLoginProfileOutput, error) {
+	resetRequired, ok := m.loginProfiles[*input.UserName]
+	if !ok {
+		return nil, &types.NoSuchEntityException{Message: aws.String("no login profile")}
+	}
+	return &iam.GetLoginProfileOutput{LoginProfile: &types.LoginProfile{PasswordResetRequired: resetRequired}}, nil
+}
+
+func (m *mockExpireClient) UpdateLoginProfile(ctx context.Context, input *iam.UpdateLoginProfileInput, optFns ...func(*iam.Options)) (*iam.UpdateLoginProfileOutput, error) {
+	if err := m.updateErr[*input.UserName]; err != nil {
+		return nil, err
+	}
+	if input.PasswordResetRequired == nil || !*input.PasswordResetRequired || input.Password != nil {
+		return nil, errors.New("unexpected update")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.updated = append(m.updated, *input.UserName)
+	return &iam.UpdateLoginProfileOutput{}, nil
+}
+
+func TestExpirePasswords(t *testing.T) {
+	client := &mockExpireClient{
+		groups: map[string][]string{"ops": {"alice", "bob", "carol", "dave", "erin", "frank"}},
+		tags: map[string]map[string]string{
+			"alice": {"team": "ops"},
+			"bob":   {"team": "ops"},
+			"carol": {"team": "ops"},
+			"dave":  {"team": "dev"},
+			"erin":  {"team": "ops"},
+			"frank": {"team": "ops"},
+		},
+		loginProfiles: map[string]bool{"alice": false, "bob": true, "dave": false, "erin": false, "frank": false},
+		updateErr:     map[string]error{"frank": errors.New("throttled")},
+	}
+
+	cmd := NewExpireCommand()
+	if err := cmd.ParseFlags([]string{"--group", "ops", "--exclude", "erin", "--tag", "team=ops"}); err != nil {
+		t.Fatal(err)
+	}
+	filter, err := expiryFilterFromFlags(cmd)
 	if err != nil {
-		// If login profile doesn't exist, create it
-		if strings.Contains(err.Error(), "NoSuchEntity") {
-			createInput := &iam.CreateLoginProfileInput{
-				UserName: aws.String(username),
-				Password: aws.String(password),
-			}
-			_, err = client.CreateLoginProfile(ctx, createInput)
-			if err != nil {
-				return err
-			}
-		} else {
-			return err
+		t.Fatal(err)
+	}
+	users, err := expiryUsers(context.Background(), cmd, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := planExpiry(context.Background(), client, users, filter, 3)
+	want := map[string]string{
+		"alice": ExpirePending,
+		"bob":   ExpireSkipped, // reset already required
+		"carol": ExpireSkipped, // no console access
+		"dave":  ExpireSkipped, // tags do not match
+		"erin":  ExpireSkipped, // excluded
+		"frank": ExpirePending,
+	}
+	for _, user := range plan {
+		if user.Result != want[user.UserName] {
+			t.Errorf("plan for %s = %s (%s), want %s", user.UserName, user.Result, user.Detail, want[user.UserName])
 		}
 	}
+	if len(client.updated) != 0 {
+		t.Errorf("Planning changed login profiles: %v", client.updated)
+	}
+
+	report := applyExpiry(context.Background(), client, plan, 3)
+	if len(client.updated) != 1 || client.updated[0] != "alice" {
+		t.Errorf("updated = %v, want [alice]", client.updated)
+	}
+	if report.Count(ExpireDone) != 1 || report.Count(ExpireFailed) != 1 || report.Count(ExpireSkipped) != 4 {
+		t.Errorf("summary = %s", report.Summary())
+	}
+	if exitcode.FromError(expiryError(report)) != exitcode.Failure {
+		t.Error("Expected a failed user to fail the command")
+	}
+}
+
+func TestExpiryUsersFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.txt")
+	if err := os.WriteFile(path, []byte("# incident 42\nalice\n\n  bob  # contractor\nalice\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewExpireCommand()
+	if err := cmd.ParseFlags([]string{"--users-file", path}); err != nil {
+		t.Fatal(err)
+	}
+	users, err := expiryUsers(context.Background(), cmd, &mockExpireClient{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(users, ",") != "alice,bob" {
+		t.Errorf("users = %v", users)
+	}
 
-	return nil
-}
\ No newline at end of file
+	cmd = NewExpireCommand()
+	if err := cmd.ParseFlags([]string{"--all-users", "--tag", "team"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := expiryFilterFromFlags(cmd); err == nil {
+		t.Error("Expected a tag without a value to fail")
+	}
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/spf13/cobra"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/executor"
+	"github.com/yourusername/iamctl/internal/exitcode"
+	"github.com/yourusername/iamctl/internal/output"
+)
+
+// consoleAPI is the subset of the IAM client used to inspect console access
+type consoleAPI interface {
+	iam.ListUsersAPIClient
+	GetUser(context.Context, *iam.GetUserInput, ...func(*iam.Options)) (*iam.GetUserOutput, error)
+	GetLoginProfile(context.Context, *iam.GetLoginProfileInput, ...func(*iam.Options)) (*iam.GetLoginProfileOutput, error)
+}
+
+// ConsoleAccess is the console sign-in state of a single IAM user
+type ConsoleAccess struct {
+	UserName         string     `json:"user_name"`
+	LoginProfile     bool       `json:"login_profile"`
+	CreateDate       *time.Time `json:"create_date,omitempty"`
+	PasswordLastUsed *time.Time `json:"password_last_used,omitempty"`
+	ResetRequired    bool       `json:"password_reset_required"`
+	UnusedDays       *int       `json:"unused_days,omitempty"`
+}
+
+// ConsoleAccessReport lists the console access of several users
+type ConsoleAccessReport []ConsoleAccess
+
+// Table implements output.Tabular
+func (r ConsoleAccessReport) Table() output.Table {
+	table := output.Table{
+		Headers: []string{"USER", "CONSOLE", "CREATED", "LAST USED", "UNUSED", "RESET REQUIRED"},
+	}
+	for _, user := range r {
+		created, lastUsed, unused := "-", "-", "-"
+		if user.CreateDate != nil {
+			created = user.CreateDate.Format("2006-01-02")
+			lastUsed = "never"
+		}
+		if user.PasswordLastUsed != nil {
+			lastUsed = user.PasswordLastUsed.Format("2006-01-02")
+		}
+		if user.UnusedDays != nil {
+			unused = fmt.Sprintf("%dd", *user.UnusedDays)
+		}
+		table.Rows = append(table.Rows, []string{
+			user.UserName,
+			strconv.FormatBool(user.LoginProfile),
+			created,
+			lastUsed,
+			unused,
+			strconv.FormatBool(user.ResetRequired),
+		})
+	}
+	return table
+}
+
+// NewStatusCommand creates the password status command
+func NewStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show console password status",
+		Long: `Show whether a user can sign in to the console: whether a login profile
+exists, when it was created, when the password was last used and whether a
+reset is required at next sign-in.
+
+With --all every user in the account is listed. --unused-days N lists only
+users whose console password has not been used for N days (counting from
+the login profile's creation when it was never used) and exits with status 2
+when there are any, so it can gate CI pipelines.`,
+		// Unused passwords are listed above the error, which root prints once
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get profile from flags or environment
+			profile, _ := cmd.Flags().GetString("profile")
+			username, _ := cmd.Flags().GetString("username")
+			all, _ := cmd.Flags().GetBool("all")
+			unusedDays, _ := cmd.Flags().GetInt("unused-days")
+			format, _ := cmd.Flags().GetString("output")
+			workers, _ := cmd.Flags().GetInt("concurrency")
+
+			if err := output.ValidateFormat(format); err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("unused-days") {
+				if unusedDays < 1 {
+					return fmt.Errorf("--unused-days must be at least 1")
+				}
+				all = true
+			}
+			if all && username != "" {
+				return fmt.Errorf("--username cannot be combined with --all or --unused-days")
+			}
+
+			// Walking a large account takes longer than a single call
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			// Create IAM client
+			client, err := awssdk.NewIAMClient(profile)
+			if err != nil {
+				return handlePasswordStatusErrors(err)
+			}
+
+			// 1. Inspect one user or the whole account
+			var report ConsoleAccessReport
+			if all {
+				report, err = buildConsoleAccessReport(ctx, client, workers, time.Now())
+			} else {
+				var user *types.User
+				user, err = lookupUser(ctx, client, username)
+				if err != nil {
+					return handlePasswordStatusErrors(err)
+				}
+				var access ConsoleAccess
+				access, err = inspectConsoleAccess(ctx, client, *user, time.Now())
+				report = ConsoleAccessReport{access}
+			}
+			if err != nil {
+				return fmt.Errorf("❌ Password status failed: %v", err)
+			}
+
+			// 2. Keep only stale console access when asked
+			if cmd.Flags().Changed("unused-days") {
+				report = report.UnusedFor(unusedDays)
+				if len(report) == 0 {
+					fmt.Printf("✅ No console passwords unused for %d days or more\n", unusedDays)
+					return nil
+				}
+			}
+
+			if err := output.Render(os.Stdout, format, report); err != nil {
+				return err
+			}
+
+			if cmd.Flags().Changed("unused-days") {
+				return exitcode.New(exitcode.Violation, fmt.Errorf("❌ %d user(s) with console passwords unused for %d days or more", len(report), unusedDays))
+			}
+			return nil
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().String("username", "", "User to show (defaults to current user)")
+	cmd.Flags().Bool("all", false, "Show every user in the account")
+	cmd.Flags().Int("unused-days", 0, "List only users whose console password is unused for this many days")
+	cmd.Flags().StringP("output", "o", "", "Output format (text, csv or json)")
+	cmd.Flags().Int("concurrency", executor.DefaultWorkers, "Number of users to inspect in parallel")
+
+	return cmd
+}
+
+// UnusedFor returns the users with a login profile unused for at least days
+func (r ConsoleAccessReport) UnusedFor(days int) ConsoleAccessReport {
+	var stale ConsoleAccessReport
+	for _, user := range r {
+		if user.UnusedDays != nil && *user.UnusedDays >= days {
+			stale = append(stale, user)
+		}
+	}
+	return stale
+}
+
+// lookupUser returns the named user, or the caller when username is empty
+func lookupUser(ctx context.Context, client consoleAPI, username string) (*types.User, error) {
+	input := &iam.GetUserInput{}
+	if username != "" {
+		input.UserName = aws.String(username)
+	}
+	output, err := client.GetUser(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return output.User, nil
+}
+
+// buildConsoleAccessReport inspects every IAM user in the account, sorted
+// by name
+func buildConsoleAccessReport(ctx context.Context, client consoleAPI, workers int, now time.Time) (ConsoleAccessReport, error) {
+	// 1. List every user, which includes when their password was last used
+	var users []types.User
+	paginator := iam.NewListUsersPaginator(client, &iam.ListUsersInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+		users = append(users, page.Users...)
+	}
+
+	// 2. Look up login profiles in parallel
+	results, errs := executor.Map(ctx, users, workers, func(ctx context.Context, user types.User) (ConsoleAccess, error) {
+		return inspectConsoleAccess(ctx, client, user, now)
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	report := ConsoleAccessReport(results)
+	sort.Slice(report, func(i, j int) bool { return report[i].UserName < report[j].UserName })
+	return report, nil
+}
+
+// inspectConsoleAccess reads the login profile of user
+func inspectConsoleAccess(ctx context.Context, client consoleAPI, user types.User, now time.Time) (ConsoleAccess, error) {
+	username := aws.ToString(user.UserName)
+	access := ConsoleAccess{UserName: username}
+
+	// A missing login profile means the user cannot sign in to the console
+	output, err := client.GetLoginProfile(ctx, &iam.GetLoginProfileInput{UserName: user.UserName})
+	if err != nil {
+		var noSuchEntity *types.NoSuchEntityException
+		if errors.As(err, &noSuchEntity) {
+			return access, nil
+		}
+		return access, fmt.Errorf("%s: failed to get login profile: %w", username, err)
+	}
+
+	access.LoginProfile = true
+	access.CreateDate = output.LoginProfile.CreateDate
+	access.ResetRequired = output.LoginProfile.PasswordResetRequired
+	access.PasswordLastUsed = user.PasswordLastUsed
+
+	// Unused time counts from creation for passwords never used
+	since := access.PasswordLastUsed
+	if since == nil || (access.CreateDate != nil && since.Before(*access.CreateDate)) {
+		since = access.CreateDate
+	}
+	if since != nil {
+		days := int(now.Sub(*since).Hours() / 24)
+		access.UnusedDays = &days
+	}
+
+	return access, nil
+}
+
+// handlePasswordStatusErrors converts SDK errors to user-friendly messages
+func handlePasswordStatusErrors(err error) error {
+	// Always return generic error message for security
+	return fmt.Errorf("❌ Password status failed: Invalid credentials")
+}
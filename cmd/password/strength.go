@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/config"
+	"github.com/yourusername/iamctl/internal/strength"
+)
+
+// addStrengthFlags adds the flag of the password strength check
+func addStrengthFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("min-score", 0, fmt.Sprintf("Reject passwords with a strength score below this (0-%d, default passwords.min_score in config.yaml, 0 disables)", strength.MaxScore))
+}
+
+// aliasLister is the subset of the IAM client used to read the account alias
+type aliasLister interface {
+	ListAccountAliases(context.Context, *iam.ListAccountAliasesInput, ...func(*iam.Options)) (*iam.ListAccountAliasesOutput, error)
+}
+
+// strengthCheck rejects passwords scoring below MinScore. A MinScore of 0
+// disables it.
+type strengthCheck struct {
+	MinScore int
+}
+
+// strengthCheckFromFlags reads the flag from addStrengthFlags, falling back
+// to config.yaml
+func strengthCheckFromFlags(cmd *cobra.Command) (strengthCheck, error) {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return strengthCheck{}, err
+	}
+
+	check := strengthCheck{MinScore: settings.Passwords.MinScore}
+	if cmd.Flags().Changed("min-score") {
+		check.MinScore, _ = cmd.Flags().GetInt("min-score")
+	}
+	if check.MinScore < 0 || check.MinScore > strength.MaxScore {
+		return strengthCheck{}, fmt.Errorf("--min-score must be between 0 and %d", strength.MaxScore)
+	}
+	return check, nil
+}
+
+// Check estimates the strength of password, discounting the user name and
+// the account alias when it can be read
+func (c strengthCheck) Check(ctx context.Context, client aliasLister, password, username string) error {
+	if c.MinScore == 0 {
+		return nil
+	}
+
+	inputs := []string{username}
+	if output, err := client.ListAccountAliases(ctx, &iam.ListAccountAliasesInput{}); err == nil {
+		inputs = append(inputs, output.AccountAliases...)
+	}
+
+	result := strength.Estimate(password, inputs...)
+	if result.Score < c.MinScore {
+		return fmt.Errorf("password is too weak (score %d of %d, minimum %d): %s",
+			result.Score, strength.MaxScore, c.MinScore, strings.Join(result.Feedback, "; "))
+	}
+	return nil
+}
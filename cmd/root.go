@@ -8,6 +8,8 @@ import (
 	password "github.com/yourusername/iamctl/cmd/password"
 	"github.com/yourusername/iamctl/cmd/mfa"
 	"github.com/spf13/cobra"
+	"github.com/yourusername/iamctl/internal/exitcode"
+	"github.com/yourusername/iamctl/internal/prompt"
 )
 
 var rootCmd = &cobra.Command{
@@ -20,13 +22,16 @@ including rotating access keys, changing passwords, and managing MFA.`,
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitcode.FromError(err))
 	}
 }
 
 func init() {
 	rootCmd.Version = "0.1.0"
 	rootCmd.Flags().BoolP("version", "v", false, "Print the version number")
+
+	// Non-interactive input for CI and scripts
+	prompt.AddFlags(rootCmd.PersistentFlags())
 	
 	// Add status command
 	rootCmd.AddCommand(NewStatusCommand())
@@ -49,6 +54,11 @@ func init() {
 	
 	resetCmd := password.NewResetCommand()
 	passwordCmd.AddCommand(resetCmd)
+	passwordCmd.AddCommand(password.NewChangeCommand())
+	passwordCmd.AddCommand(password.NewStatusCommand())
+	passwordCmd.AddCommand(password.NewDisableCommand())
+	passwordCmd.AddCommand(password.NewExpireCommand())
+	passwordCmd.AddCommand(password.NewPolicyCommand())
 	rootCmd.AddCommand(passwordCmd)
 	
 	// Add MFA commands
@@ -60,6 +70,11 @@ func init() {
 	mfaCmd.AddCommand(mfa.NewEnableCommand())
 	mfaCmd.AddCommand(mfa.NewDisableCommand())
 	mfaCmd.AddCommand(mfa.NewStatusCommand())
+	mfaCmd.AddCommand(mfa.NewRotateCommand())
+	mfaCmd.AddCommand(mfa.NewResyncCommand())
+	mfaCmd.AddCommand(mfa.NewReportCommand())
+	mfaCmd.AddCommand(mfa.NewSeedCommand())
+	mfaCmd.AddCommand(mfa.NewCodeCommand())
 	rootCmd.AddCommand(mfaCmd)
 	
 	// Add enforce commands
@@ -71,4 +86,13 @@ func init() {
 	enforceCmd.AddCommand(enforce.NewMFACommand())
 	enforceCmd.AddCommand(enforce.NewPolicyCommand())
 	rootCmd.AddCommand(enforceCmd)
+
+	// Add two-person approval commands
+	rootCmd.AddCommand(NewApproveCommand())
+	rootCmd.AddCommand(NewExecuteCommand())
+
+	// Add MFA session commands
+	rootCmd.AddCommand(NewSessionCommand())
+	rootCmd.AddCommand(NewCredentialProcessCommand())
+	rootCmd.AddCommand(NewConfigureCommand())
 }
\ No newline at end of file
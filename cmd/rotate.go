@@ -11,6 +11,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/prompt"
 	"github.com/spf13/cobra"
 )
 
@@ -19,22 +21,40 @@ func NewRotateCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "rotate",
 		Short: "Rotate IAM access keys",
-		Long:  `Rotate IAM access keys by creating a new key, testing it, storing it in Secrets Manager, and deleting the old key.`,
+		Long: `Rotate IAM access keys by creating a new key, testing it, storing it in Secrets Manager, and deleting the old key.
+
+With --all-profiles, every profile holding a long-term key in the shared
+credentials file is checked instead. Keys older than --max-age days are
+replaced and the credentials file is updated in place. Each profile whose key
+is due asks for a current MFA code before any key is changed.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Get profile from flags or environment
 			profile, _ := cmd.Flags().GetString("profile")
 			secretName, _ := cmd.Flags().GetString("secret-name")
+			allProfiles, _ := cmd.Flags().GetBool("all-profiles")
+			maxAgeDays, _ := cmd.Flags().GetInt("max-age")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			mfaSerial, _ := cmd.Flags().GetString("mfa-serial")
+
+			// Rotate every local static-key profile
+			if allProfiles {
+				if maxAgeDays < 1 {
+					return fmt.Errorf("max-age must be at least 1 day")
+				}
+				return rotateAllProfiles(time.Duration(maxAgeDays)*24*time.Hour, dryRun, mfaSerial)
+			}
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
-
-			// Create IAM client
-			iamClient, err := awssdk.NewIAMClient(profile)
+			// Prove possession of a current MFA code before the API deadline starts
+			session, err := awssdk.RequireMFA(context.Background(), profile, mfaSerial, mfatoken.ForProfile(profile, prompt.MFACode))
 			if err != nil {
 				return handleRotateAWSErrors(err)
 			}
 
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+			iamClient := session.IAMClient()
+
 			// Get current user
 			user, err := awssdk.GetCurrentUser(ctx, iamClient)
 			if err != nil {
@@ -55,6 +75,10 @@ func NewRotateCommand() *cobra.Command {
 	// Add flags
 	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
 	cmd.Flags().String("secret-name", "iamctl/access-key", "Name of the secret in AWS Secrets Manager")
+	cmd.Flags().String("mfa-serial", "", "MFA device serial number (defaults to your only MFA device)")
+	cmd.Flags().Bool("all-profiles", false, "Check and rotate every static-key profile in the credentials file")
+	cmd.Flags().Int("max-age", 90, "Rotate keys older than this many days (with --all-profiles)")
+	cmd.Flags().Bool("dry-run", false, "Report key ages without rotating (with --all-profiles)")
 
 	return cmd
 }
@@ -160,6 +184,8 @@ func handleRotateAWSErrors(err error) error {
 		return fmt.Errorf("credential error: check your AWS credentials configuration")
 	case *awssdk.PermissionError:
 		return fmt.Errorf("permission error: you don't have sufficient permissions to rotate keys")
+	case *awssdk.MFAError:
+		return fmt.Errorf("❌ MFA verification failed: %v", err)
 	default:
 		return fmt.Errorf("AWS service error: cannot connect to IAM service")
 	}
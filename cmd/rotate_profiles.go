@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/awsfile"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/prompt"
+)
+
+// accessKeyAPI is the subset of the IAM client used to rotate access keys
+type accessKeyAPI interface {
+	GetUser(context.Context, *iam.GetUserInput, ...func(*iam.Options)) (*iam.GetUserOutput, error)
+	ListAccessKeys(context.Context, *iam.ListAccessKeysInput, ...func(*iam.Options)) (*iam.ListAccessKeysOutput, error)
+	CreateAccessKey(context.Context, *iam.CreateAccessKeyInput, ...func(*iam.Options)) (*iam.CreateAccessKeyOutput, error)
+	UpdateAccessKey(context.Context, *iam.UpdateAccessKeyInput, ...func(*iam.Options)) (*iam.UpdateAccessKeyOutput, error)
+	DeleteAccessKey(context.Context, *iam.DeleteAccessKeyInput, ...func(*iam.Options)) (*iam.DeleteAccessKeyOutput, error)
+}
+
+// keyVerifier checks that a key pair can authenticate against IAM
+type keyVerifier func(ctx context.Context, accessKeyID, secretAccessKey string) error
+
+// mfaGate proves possession of an MFA code for a profile and returns a client
+// whose calls carry MFA context
+type mfaGate func() (accessKeyAPI, error)
+
+// profileTimeout bounds the AWS calls made to check one profile's key, and
+// separately those made to rotate it, so one slow account cannot starve the
+// rest
+const profileTimeout = 60 * time.Second
+
+// profileRotation is the outcome of checking a single profile
+type profileRotation struct {
+	Profile string
+	KeyID   string
+	Age     time.Duration
+	Result  string
+	Err     error
+}
+
+// rotateAllProfiles checks every static-key profile in the credentials file
+// and rotates the keys older than maxAge. Each rotation is gated on an MFA
+// code for that profile; profiles with nothing to rotate do not prompt.
+func rotateAllProfiles(maxAge time.Duration, dryRun bool, mfaSerial string) error {
+	creds, err := awsfile.Load(awsfile.CredentialsPath())
+	if err != nil {
+		return err
+	}
+
+	profiles := creds.StaticKeyProfiles()
+	if len(profiles) == 0 {
+		fmt.Printf("No profiles with long-term access keys found in %s\n", creds.Path())
+		return nil
+	}
+
+	var results []profileRotation
+	for _, profile := range profiles {
+		loadCtx, cancel := context.WithTimeout(context.Background(), profileTimeout)
+		client, err := newStaticKeyClient(loadCtx, profile.Name, profile.AccessKeyID, profile.SecretAccessKey)
+		cancel()
+		if err != nil {
+			results = append(results, profileRotation{
+				Profile: profile.Name,
+				KeyID:   profile.AccessKeyID,
+				Result:  "skipped",
+				Err:     err,
+			})
+			continue
+		}
+
+		verify := func(ctx context.Context, accessKeyID, secretAccessKey string) error {
+			return verifyStaticKey(ctx, profile.Name, accessKeyID, secretAccessKey)
+		}
+		requireMFA := func() (accessKeyAPI, error) {
+			fmt.Printf("Profile %s: key is due for rotation\n", profile.Name)
+			session, err := awssdk.RequireMFA(context.Background(), profile.Name, mfaSerial, mfatoken.ForProfile(profile.Name, prompt.MFACode))
+			if err != nil {
+				return nil, err
+			}
+			return session.IAMClient(), nil
+		}
+		results = append(results, rotateProfileKey(context.Background(), client, creds, profile, maxAge, dryRun, verify, requireMFA))
+	}
+
+	printRotationSummary(results)
+
+	for _, result := range results {
+		if result.Result == "failed" {
+			return fmt.Errorf("❌ Rotation failed for one or more profiles")
+		}
+	}
+	return nil
+}
+
+// rotateProfileKey verifies a profile's key, checks its age and rotates it
+// when it is older than maxAge. Keys are only created and deleted after
+// requireMFA succeeds, and the credentials file is only rewritten once the
+// replacement key has been verified.
+//
+// requireMFA waits on the user, so ctx should carry no deadline: checking
+// the key and rotating it each get profileTimeout, and the prompt between
+// them is not timed.
+func rotateProfileKey(ctx context.Context, client accessKeyAPI, creds *awsfile.File, profile awsfile.StaticProfile, maxAge time.Duration, dryRun bool, verify keyVerifier, requireMFA mfaGate) profileRotation {
+	result := profileRotation{Profile: profile.Name, KeyID: profile.AccessKeyID}
+	parent := ctx
+
+	ctx, cancel := context.WithTimeout(parent, profileTimeout)
+	defer cancel()
+
+	// 1. Verify the current key before touching anything
+	userOutput, err := client.GetUser(ctx, &iam.GetUserInput{})
+	if err != nil {
+		result.Result = "skipped"
+		result.Err = fmt.Errorf("current key failed verification: %w", err)
+		return result
+	}
+	username := userOutput.User.UserName
+
+	// 2. Find the key's metadata to learn its age
+	listResult, err := client.ListAccessKeys(ctx, &iam.ListAccessKeysInput{UserName: username})
+	if err != nil {
+		result.Result = "skipped"
+		result.Err = fmt.Errorf("failed to list access keys: %w", err)
+		return result
+	}
+
+	var current *types.AccessKeyMetadata
+	for i, key := range listResult.AccessKeyMetadata {
+		if aws.ToString(key.AccessKeyId) == profile.AccessKeyID {
+			current = &listResult.AccessKeyMetadata[i]
+			break
+		}
+	}
+	if current == nil || current.CreateDate == nil {
+		result.Result = "skipped"
+		result.Err = fmt.Errorf("key not found for user %s", aws.ToString(username))
+		return result
+	}
+	result.Age = time.Since(*current.CreateDate)
+
+	if result.Age < maxAge {
+		result.Result = "ok"
+		return result
+	}
+	if dryRun {
+		result.Result = "due"
+		return result
+	}
+
+	// IAM allows two keys per user, so a second key blocks rotation
+	if len(listResult.AccessKeyMetadata) > 1 {
+		result.Result = "skipped"
+		result.Err = fmt.Errorf("user %s already has two access keys", aws.ToString(username))
+		return result
+	}
+
+	// 3. Prove possession of a current MFA code before changing any key
+	cancel()
+	client, err = requireMFA()
+	if err != nil {
+		result.Result = "skipped"
+		result.Err = fmt.Errorf("MFA verification failed: %w", err)
+		return result
+	}
+	ctx, cancel = context.WithTimeout(parent, profileTimeout)
+	defer cancel()
+
+	// 4. Create and verify the replacement key
+	createResult, err := client.CreateAccessKey(ctx, &iam.CreateAccessKeyInput{UserName: username})
+	if err != nil {
+		result.Result = "failed"
+		result.Err = fmt.Errorf("failed to create new access key: %w", err)
+		return result
+	}
+	newKey := createResult.AccessKey
+
+	discardNewKey := func() {
+		_, deleteErr := client.DeleteAccessKey(ctx, &iam.DeleteAccessKeyInput{
+			AccessKeyId: newKey.AccessKeyId,
+			UserName:    username,
+		})
+		if deleteErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to clean up new access key for profile %s: %v\n", profile.Name, deleteErr)
+		}
+	}
+
+	if err := verify(ctx, aws.ToString(newKey.AccessKeyId), aws.ToString(newKey.SecretAccessKey)); err != nil {
+		discardNewKey()
+		result.Result = "failed"
+		result.Err = fmt.Errorf("new key failed verification: %w", err)
+		return result
+	}
+
+	// 5. Persist the new key before retiring the old one
+	creds.SetStaticKey(profile.Name, aws.ToString(newKey.AccessKeyId), aws.ToString(newKey.SecretAccessKey))
+	if err := creds.Save(); err != nil {
+		creds.SetStaticKey(profile.Name, profile.AccessKeyID, profile.SecretAccessKey)
+		discardNewKey()
+		result.Result = "failed"
+		result.Err = err
+		return result
+	}
+	result.KeyID = aws.ToString(newKey.AccessKeyId)
+
+	// 6. Deactivate, then delete, the old key
+	_, err = client.UpdateAccessKey(ctx, &iam.UpdateAccessKeyInput{
+		AccessKeyId: current.AccessKeyId,
+		UserName:    username,
+		Status:      types.StatusTypeInactive,
+	})
+	if err == nil {
+		_, err = client.DeleteAccessKey(ctx, &iam.DeleteAccessKeyInput{
+			AccessKeyId: current.AccessKeyId,
+			UserName:    username,
+		})
+	}
+	if err != nil {
+		result.Result = "rotated"
+		result.Err = fmt.Errorf("old key %s still present: %w", maskKeyID(profile.AccessKeyID), err)
+		return result
+	}
+
+	result.Result = "rotated"
+	return result
+}
+
+// newStaticKeyClient creates an IAM client that authenticates with the given
+// key pair, taking region and other settings from the profile
+func newStaticKeyClient(ctx context.Context, profile, accessKeyID, secretAccessKey string) (*iam.Client, error) {
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithSharedConfigProfile(profile),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return iam.NewFromConfig(cfg), nil
+}
+
+// verifyStaticKey retries GetUser with a freshly created key, since new keys
+// take a few seconds to propagate through IAM
+func verifyStaticKey(ctx context.Context, profile, accessKeyID, secretAccessKey string) error {
+	client, err := newStaticKeyClient(ctx, profile, accessKeyID, secretAccessKey)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		_, err = client.GetUser(ctx, &iam.GetUserInput{})
+		if err == nil || attempt == 5 {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// printRotationSummary prints a per-profile table of rotation outcomes
+func printRotationSummary(results []profileRotation) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROFILE\tKEY\tAGE\tRESULT")
+	for _, result := range results {
+		age := "-"
+		if result.Age > 0 {
+			age = fmt.Sprintf("%dd", int(result.Age.Hours()/24))
+		}
+		outcome := result.Result
+		if result.Err != nil {
+			outcome = fmt.Sprintf("%s (%v)", outcome, sanitizeError(result.Err))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Profile, maskKeyID(result.KeyID), age, outcome)
+	}
+	w.Flush()
+}
+
+// maskKeyID hides all but the last four characters of an access key ID
+func maskKeyID(keyID string) string {
+	if len(keyID) <= 4 {
+		return "****"
+	}
+	return "****" + keyID[len(keyID)-4:]
+}
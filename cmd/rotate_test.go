@@ -4,53 +4,58 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
 	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/awsfile"
 )
 
 // Mock IAM client for testing
 type mockIAMClient struct {
-	createKeyFunc  func(context.Context, *iam.CreateAccessKeyInput) (*iam.CreateAccessKeyOutput, error)
-	deleteKeyFunc  func(context.Context, *iam.DeleteAccessKeyInput) (*iam.DeleteAccessKeyOutput, error)
-	listKeysFunc   func(context.Context, *iam.ListAccessKeysInput) (*iam.ListAccessKeysOutput, error)
-	updateKeyFunc  func(context.Context, *iam.UpdateAccessKeyInput) (*iam.UpdateAccessKeyOutput, error)
-	getUserFunc    func(context.Context, *iam.GetUserInput) (*iam.GetUserOutput, error)
+	createKeyFunc func(context.Context, *iam.CreateAccessKeyInput) (*iam.CreateAccessKeyOutput, error)
+	deleteKeyFunc func(context.Context, *iam.DeleteAccessKeyInput) (*iam.DeleteAccessKeyOutput, error)
+	listKeysFunc  func(context.Context, *iam.ListAccessKeysInput) (*iam.ListAccessKeysOutput, error)
+	updateKeyFunc func(context.Context, *iam.UpdateAccessKeyInput) (*iam.UpdateAccessKeyOutput, error)
+	getUserFunc   func(context.Context, *iam.GetUserInput) (*iam.GetUserOutput, error)
 }
 
-func (m *mockIAMClient) CreateAccessKey(ctx context.Context, input *iam.CreateAccessKeyInput) (*iam.CreateAccessKeyOutput, error) {
+func (m *mockIAMClient) CreateAccessKey(ctx context.Context, input *iam.CreateAccessKeyInput, optFns ...func(*iam.Options)) (*iam.CreateAccessKeyOutput, error) {
 	if m.createKeyFunc != nil {
 		return m.createKeyFunc(ctx, input)
 	}
 	return nil, nil
 }
 
-func (m *mockIAMClient) DeleteAccessKey(ctx context.Context, input *iam.DeleteAccessKeyInput) (*iam.DeleteAccessKeyOutput, error) {
+func (m *mockIAMClient) DeleteAccessKey(ctx context.Context, input *iam.DeleteAccessKeyInput, optFns ...func(*iam.Options)) (*iam.DeleteAccessKeyOutput, error) {
 	if m.deleteKeyFunc != nil {
 		return m.deleteKeyFunc(ctx, input)
 	}
 	return nil, nil
 }
 
-func (m *mockIAMClient) ListAccessKeys(ctx context.Context, input *iam.ListAccessKeysInput) (*iam.ListAccessKeysOutput, error) {
+func (m *mockIAMClient) ListAccessKeys(ctx context.Context, input *iam.ListAccessKeysInput, optFns ...func(*iam.Options)) (*iam.ListAccessKeysOutput, error) {
 	if m.listKeysFunc != nil {
 		return m.listKeysFunc(ctx, input)
 	}
 	return nil, nil
 }
 
-func (m *mockIAMClient) UpdateAccessKey(ctx context.Context, input *iam.UpdateAccessKeyInput) (*iam.UpdateAccessKeyOutput, error) {
+func (m *mockIAMClient) UpdateAccessKey(ctx context.Context, input *iam.UpdateAccessKeyInput, optFns ...func(*iam.Options)) (*iam.UpdateAccessKeyOutput, error) {
 	if m.updateKeyFunc != nil {
 		return m.updateKeyFunc(ctx, input)
 	}
 	return nil, nil
 }
 
-func (m *mockIAMClient) GetUser(ctx context.Context, input *iam.GetUserInput) (*iam.GetUserOutput, error) {
+func (m *mockIAMClient) GetUser(ctx context.Context, input *iam.GetUserInput, optFns ...func(*iam.Options)) (*iam.GetUserOutput, error) {
 	if m.getUserFunc != nil {
 		return m.getUserFunc(ctx, input)
 	}
@@ -225,8 +230,8 @@ func rotateKeysWithClients(ctx context.Context, iamClient *mockIAMClient, smClie
 
 	// 3. Store new key in Secrets Manager
 	_, err = smClient.CreateSecret(ctx, &secretsmanager.CreateSecretInput{
-		Name:         aws.String(secretName),
-		SecretString: aws.String(fmt.Sprintf("{\"AccessKeyId\": \"%s\", \"SecretAccessKey\": \"%s\"}", 
+		Name: aws.String(secretName),
+		SecretString: aws.String(fmt.Sprintf("{\"AccessKeyId\": \"%s\", \"SecretAccessKey\": \"%s\"}",
 			*newKey.AccessKeyId, *newKey.SecretAccessKey)),
 	})
 	if err != nil {
@@ -258,4 +263,139 @@ func rotateKeysWithClients(ctx context.Context, iamClient *mockIAMClient, smClie
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func TestRotateProfileKey(t *testing.T) {
+	credsPath := filepath.Join(t.TempDir(), "credentials")
+	err := os.WriteFile(credsPath, []byte("[work]\naws_access_key_id = AKIA_OLD_KEY\naws_secret_access_key = old_secret\nregion = us-east-1\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newIAMClient := func(deleted *[]string) *mockIAMClient {
+		return &mockIAMClient{
+			getUserFunc: func(ctx context.Context, input *iam.GetUserInput) (*iam.GetUserOutput, error) {
+				return &iam.GetUserOutput{User: &types.User{UserName: aws.String("testuser")}}, nil
+			},
+			listKeysFunc: func(ctx context.Context, input *iam.ListAccessKeysInput) (*iam.ListAccessKeysOutput, error) {
+				return &iam.ListAccessKeysOutput{
+					AccessKeyMetadata: []types.AccessKeyMetadata{
+						{
+							AccessKeyId: aws.String("AKIA_OLD_KEY"),
+							CreateDate:  aws.Time(time.Now().AddDate(0, 0, -120)),
+						},
+					},
+				}, nil
+			},
+			createKeyFunc: func(ctx context.Context, input *iam.CreateAccessKeyInput) (*iam.CreateAccessKeyOutput, error) {
+				return &iam.CreateAccessKeyOutput{
+					AccessKey: &types.AccessKey{
+						AccessKeyId:     aws.String("AKIA_NEW_KEY"),
+						SecretAccessKey: aws.String("new_secret"),
+					},
+				}, nil
+			},
+			updateKeyFunc: func(ctx context.Context, input *iam.UpdateAccessKeyInput) (*iam.UpdateAccessKeyOutput, error) {
+				return &iam.UpdateAccessKeyOutput{}, nil
+			},
+			deleteKeyFunc: func(ctx context.Context, input *iam.DeleteAccessKeyInput) (*iam.DeleteAccessKeyOutput, error) {
+				*deleted = append(*deleted, *input.AccessKeyId)
+				return &iam.DeleteAccessKeyOutput{}, nil
+			},
+		}
+	}
+	profile := awsfile.StaticProfile{Name: "work", AccessKeyID: "AKIA_OLD_KEY", SecretAccessKey: "old_secret"}
+	ctx := context.Background()
+
+	t.Run("new key fails verification", func(t *testing.T) {
+		var deleted []string
+		creds, err := awsfile.Load(credsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		verify := func(ctx context.Context, accessKeyID, secretAccessKey string) error {
+			return errors.New("InvalidClientTokenId")
+		}
+
+		client := newIAMClient(&deleted)
+		requireMFA := func() (accessKeyAPI, error) { return client, nil }
+
+		result := rotateProfileKey(ctx, client, creds, profile, 90*24*time.Hour, false, verify, requireMFA)
+		assert.Equal(t, "failed", result.Result)
+		assert.Equal(t, []string{"AKIA_NEW_KEY"}, deleted)
+
+		reloaded, err := awsfile.Load(credsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyID, _ := reloaded.Get("work", "aws_access_key_id")
+		assert.Equal(t, "AKIA_OLD_KEY", keyID)
+	})
+
+	t.Run("old key is rotated", func(t *testing.T) {
+		var deleted []string
+		creds, err := awsfile.Load(credsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		verify := func(ctx context.Context, accessKeyID, secretAccessKey string) error {
+			return nil
+		}
+
+		client := newIAMClient(&deleted)
+		requireMFA := func() (accessKeyAPI, error) { return client, nil }
+
+		result := rotateProfileKey(ctx, client, creds, profile, 90*24*time.Hour, false, verify, requireMFA)
+		assert.Equal(t, "rotated", result.Result)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"AKIA_OLD_KEY"}, deleted)
+
+		reloaded, err := awsfile.Load(credsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := reloaded.Section("work")
+		assert.Equal(t, "AKIA_NEW_KEY", values["aws_access_key_id"])
+		assert.Equal(t, "new_secret", values["aws_secret_access_key"])
+		assert.Equal(t, "us-east-1", values["region"])
+	})
+
+	t.Run("current key fails verification", func(t *testing.T) {
+		client := &mockIAMClient{
+			getUserFunc: func(ctx context.Context, input *iam.GetUserInput) (*iam.GetUserOutput, error) {
+				return nil, &awssdk.CredentialError{Err: errors.New("invalid token")}
+			},
+		}
+		creds, err := awsfile.Load(credsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := rotateProfileKey(ctx, client, creds, profile, 90*24*time.Hour, false, nil, nil)
+		assert.Equal(t, "skipped", result.Result)
+		assert.Error(t, result.Err)
+	})
+
+	t.Run("MFA verification fails", func(t *testing.T) {
+		var deleted []string
+		creds, err := awsfile.Load(credsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requireMFA := func() (accessKeyAPI, error) {
+			return nil, &awssdk.MFAError{Err: errors.New("invalid MFA code")}
+		}
+
+		result := rotateProfileKey(ctx, newIAMClient(&deleted), creds, profile, 90*24*time.Hour, false, nil, requireMFA)
+		assert.Equal(t, "skipped", result.Result)
+		assert.Error(t, result.Err)
+		assert.Empty(t, deleted)
+
+		reloaded, err := awsfile.Load(credsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyID, _ := reloaded.Get("work", "aws_access_key_id")
+		assert.Equal(t, "AKIA_NEW_KEY", keyID)
+	})
+}
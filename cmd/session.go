@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	awssdk "github.com/yourusername/iamctl/internal/aws"
+	"github.com/yourusername/iamctl/internal/keyring"
+	"github.com/yourusername/iamctl/internal/mfatoken"
+	"github.com/yourusername/iamctl/internal/prompt"
+	"github.com/yourusername/iamctl/internal/session"
+)
+
+// NewSessionCommand creates the session command and its subcommands
+func NewSessionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "session",
+		Short: "Manage cached MFA sessions",
+		Long: `Start an MFA-backed session once and reuse it for later iamctl commands.
+Session credentials are cached encrypted under ~/.iamctl until they expire,
+with the key held in the OS keyring (the macOS Keychain, the Secret Service
+through secret-tool on Linux, or the Windows Credential Manager).`,
+	}
+
+	cmd.AddCommand(newSessionStartCommand())
+	cmd.AddCommand(newSessionStatusCommand())
+	cmd.AddCommand(newSessionEndCommand())
+
+	return cmd
+}
+
+func newSessionStartCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start an MFA session and cache its credentials",
+		Long: `Obtain temporary credentials with a current MFA code, using
+sts:GetSessionToken or, with --role-arn, sts:AssumeRole. Until they expire,
+iamctl commands run with the profile use them without asking for MFA again.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := sessionOptionsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			// StartSession bounds each AWS call itself, so the MFA prompt is not timed
+			entry, err := awssdk.StartSession(context.Background(), opts.Profile, opts.MFASerial, opts.RoleARN, opts.Duration,
+				mfatoken.ForProfile(opts.Profile, prompt.MFACode))
+			if err != nil {
+				return handleSessionErrors(err)
+			}
+
+			fmt.Printf("✅ Session started for profile %s (expires %s)\n",
+				entry.Profile, entry.Expiration.Local().Format("2006-01-02 15:04:05 MST"))
+			return nil
+		},
+	}
+
+	addSessionFlags(cmd)
+
+	return cmd
+}
+
+func newSessionStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show cached MFA sessions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := session.List()
+			if err != nil {
+				return fmt.Errorf("❌ Failed to read sessions: %v", err)
+			}
+
+			if len(entries) == 0 {
+				fmt.Println("No active sessions")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PROFILE\tKIND\tROLE\tMFA DEVICE\tEXPIRES\tREMAINING")
+			for _, entry := range entries {
+				role := entry.RoleARN
+				if role == "" {
+					role = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+					entry.Profile,
+					entry.Kind,
+					role,
+					entry.SerialNumber,
+					entry.Expiration.Local().Format("2006-01-02 15:04:05 MST"),
+					time.Until(entry.Expiration).Round(time.Minute))
+			}
+			return w.Flush()
+		},
+	}
+
+	return cmd
+}
+
+func newSessionEndCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "end",
+		Short: "Remove a cached MFA session",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, _ := cmd.Flags().GetString("profile")
+			all, _ := cmd.Flags().GetBool("all")
+
+			profiles := []string{session.ProfileName(profile)}
+			if all {
+				entries, err := session.List()
+				if err != nil {
+					return fmt.Errorf("❌ Failed to read sessions: %v", err)
+				}
+				profiles = profiles[:0]
+				for _, entry := range entries {
+					profiles = append(profiles, entry.Profile)
+				}
+			}
+
+			for _, p := range profiles {
+				if err := session.Delete(p); err != nil {
+					return fmt.Errorf("❌ Failed to end session: %v", err)
+				}
+				fmt.Printf("✅ Session ended for profile %s\n", p)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("profile", "p", "", "Use a specific profile from your credential file")
+	cmd.Flags().Bool("all", false, "End every cached session")
+
+	return cmd
+}
+
+// handleSessionErrors reports a missing OS keyring as such rather than as an
+// AWS error
+func handleSessionErrors(err error) error {
+	if errors.Is(err, keyring.ErrUnavailable) {
+		return fmt.Errorf("❌ Session not cached: %v", err)
+	}
+	return handleAWSErrors(err)
+}
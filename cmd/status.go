@@ -5,11 +5,11 @@ import (
 	"encoding/csv"
 	"fmt"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/yourusername/iamctl/internal/arn"
 	"github.com/yourusername/iamctl/internal/aws"
 	"github.com/spf13/cobra"
 )
@@ -51,8 +51,8 @@ func NewStatusCommand() *cobra.Command {
 
 			// 7. Prepare user information
 			userName := *user.UserName
-			arn := *user.Arn
-			accountID := extractAccountID(*user.Arn)
+			userARN := *user.Arn
+			accountID := accountIDFromARN(userARN)
 			
 			// 8. Check MFA status (security feature)
 			mfaStatus := "disabled"
@@ -73,14 +73,14 @@ func NewStatusCommand() *cobra.Command {
 				}
 				
 				// Write data
-				record := []string{userName, arn, accountID, mfaStatus}
+				record := []string{userName, userARN, accountID, mfaStatus}
 				if err := writer.Write(record); err != nil {
 					return fmt.Errorf("failed to write CSV record: %w", err)
 				}
 			} else {
 				// Default text output (security-conscious formatting)
 				fmt.Printf("User: %s\n", userName)
-				fmt.Printf("ARN: %s\n", arn)
+				fmt.Printf("ARN: %s\n", userARN)
 				fmt.Printf("Account ID: %s\n", accountID)
 				fmt.Printf("MFA: %s\n", mfaStatus)
 			}
@@ -105,19 +105,21 @@ func handleAWSErrors(err error) error {
 		return fmt.Errorf("credential error: check your AWS credentials configuration")
 	case *aws.PermissionError:
 		return fmt.Errorf("permission error: you don't have sufficient permissions to get user information")
+	case *aws.MFAError:
+		return fmt.Errorf("❌ MFA verification failed: %v", err)
 	default:
 		return fmt.Errorf("AWS service error: cannot connect to IAM service")
 	}
 }
 
-// Helper function to extract account ID from ARN
-func extractAccountID(arn string) string {
-	// arn:aws:iam::123456789012:user/username
-	parts := strings.Split(arn, ":")
-	if len(parts) >= 5 {
-		return parts[4]
+// accountIDFromARN returns the account ID of an ARN in any partition, or
+// "unknown" when it cannot be parsed
+func accountIDFromARN(s string) string {
+	parsed, err := arn.Parse(s)
+	if err != nil {
+		return "unknown"
 	}
-	return "unknown"
+	return parsed.AccountID
 }
 
 // Check if MFA is enabled for the current user
@@ -7,7 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestExtractAccountID(t *testing.T) {
+func TestAccountIDFromARN(t *testing.T) {
 	tests := []struct {
 		name     string
 		arn      string
@@ -18,16 +18,31 @@ func TestExtractAccountID(t *testing.T) {
 			arn:      "arn:aws:iam::123456789012:user/test-user",
 			expected: "123456789012",
 		},
+		{
+			name:     "GovCloud ARN",
+			arn:      "arn:aws-us-gov:iam::123456789012:user/test-user",
+			expected: "123456789012",
+		},
+		{
+			name:     "China ARN",
+			arn:      "arn:aws-cn:sts::123456789012:assumed-role/Admin/test-user",
+			expected: "123456789012",
+		},
 		{
 			name:     "invalid ARN",
 			arn:      "invalid-arn",
 			expected: "unknown",
 		},
+		{
+			name:     "too few fields",
+			arn:      "arn:aws:iam::123456789012",
+			expected: "unknown",
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			result := extractAccountID(tc.arn)
+			result := accountIDFromARN(tc.arn)
 			assert.Equal(t, tc.expected, result)
 		})
 	}
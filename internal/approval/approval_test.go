@@ -0,0 +1,236 @@
+package approval
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+)
+
+const testRequester = "arn:aws:iam::123456789012:user/alice"
+
+// setupSigners creates alice's and bob's keys in separate state directories,
+// as on two operators' machines, and trusts both in alice's. The test ends in
+// alice's state directory.
+func setupSigners(t *testing.T) (*Signer, *Signer) {
+	Register("test.op", func(ctx context.Context, client *iam.Client, params map[string]string) error {
+		return nil
+	})
+
+	var signers []*Signer
+	var pubPaths []string
+	for _, name := range []string{"bob", "alice"} {
+		t.Setenv("IAMCTL_HOME", t.TempDir())
+		pubPath, err := GenerateKey(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, err := LoadSigner(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers = append(signers, signer)
+		pubPaths = append(pubPaths, pubPath)
+	}
+
+	for _, path := range pubPaths {
+		public, err := ReadPublicKey(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Trust(public); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return signers[1], signers[0]
+}
+
+// localKeys returns the signing keys held in the current state directory
+func localKeys(t *testing.T) []PublicKey {
+	local, err := LocalKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return local
+}
+
+func TestTwoPersonApproval(t *testing.T) {
+	alice, bob := setupSigners(t)
+	trusted, err := TrustedKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := NewRequest("test.op", map[string]string{"username": "carol"}, alice, testRequester, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := req.Verify(trusted, localKeys(t)); err == nil {
+		t.Error("expected unapproved request to fail verification")
+	}
+	if err := req.Approve(alice); err == nil {
+		t.Error("expected self-approval to be rejected")
+	}
+	if err := req.Approve(bob); err != nil {
+		t.Fatalf("expected approval to succeed, got %v", err)
+	}
+
+	// Round-trip through the request file
+	path := filepath.Join(t.TempDir(), "request.json")
+	if err := req.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Verify(trusted, localKeys(t)); err != nil {
+		t.Errorf("expected approved request to verify, got %v", err)
+	}
+
+	// Tampering with the parameters invalidates both signatures
+	loaded.Parameters["username"] = "mallory"
+	if err := loaded.Verify(trusted, localKeys(t)); err == nil {
+		t.Error("expected tampered request to fail verification")
+	}
+}
+
+func TestExpiredRequest(t *testing.T) {
+	alice, bob := setupSigners(t)
+
+	req, err := NewRequest("test.op", map[string]string{}, alice, testRequester, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := req.Approve(bob); err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-sign with an expiry in the past so only the expiry check fails
+	req.ExpiresAt = time.Now().Add(-time.Minute).UTC()
+	req.Signatures = nil
+	if err := req.sign(RoleRequester, alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := req.sign(RoleApprover, bob); err != nil {
+		t.Fatal(err)
+	}
+
+	trusted, err := TrustedKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := req.Verify(trusted, localKeys(t)); err == nil {
+		t.Error("expected expired request to fail verification")
+	}
+}
+
+func TestUntrustedApprover(t *testing.T) {
+	alice, bob := setupSigners(t)
+
+	req, err := NewRequest("test.op", map[string]string{}, alice, testRequester, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := req.Approve(bob); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := req.Verify([]PublicKey{alice.Public()}, localKeys(t)); err == nil {
+		t.Error("expected request approved by an untrusted key to fail verification")
+	}
+}
+
+func TestGeneratedKeysAreNotTrusted(t *testing.T) {
+	t.Setenv("IAMCTL_HOME", t.TempDir())
+	if _, err := GenerateKey("alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	trusted, err := TrustedKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trusted) != 0 {
+		t.Errorf("trusted = %v, want no keys until imported", trusted)
+	}
+}
+
+func TestOneOperatorCannotSignTwice(t *testing.T) {
+	t.Setenv("IAMCTL_HOME", t.TempDir())
+	Register("test.op", func(ctx context.Context, client *iam.Client, params map[string]string) error {
+		return nil
+	})
+
+	// One operator creates and trusts two keys on the same machine
+	var signers []*Signer
+	for _, name := range []string{"a", "b"} {
+		pubPath, err := GenerateKey(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		public, err := ReadPublicKey(pubPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Trust(public); err != nil {
+			t.Fatal(err)
+		}
+		signer, err := LoadSigner(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers = append(signers, signer)
+	}
+
+	req, err := NewRequest("test.op", map[string]string{}, signers[0], testRequester, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := req.Approve(signers[1]); err != nil {
+		t.Fatal(err)
+	}
+	trusted, err := TrustedKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := req.Verify(trusted, localKeys(t)); err == nil {
+		t.Error("expected a request signed with two local keys to fail verification")
+	}
+}
+
+func TestClaimOnce(t *testing.T) {
+	alice, bob := setupSigners(t)
+
+	req, err := NewRequest("test.op", map[string]string{}, alice, testRequester, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := req.Approve(bob); err != nil {
+		t.Fatal(err)
+	}
+
+	executor := "arn:aws:iam::123456789012:user/bob"
+	if err := Claim(req, executor); err != nil {
+		t.Fatal(err)
+	}
+	if err := Claim(req, executor); err == nil {
+		t.Error("expected a second execution to be refused")
+	}
+
+	// A failed execution releases the claim for a retry
+	if err := Release(req); err != nil {
+		t.Fatal(err)
+	}
+	if err := Claim(req, executor); err != nil {
+		t.Errorf("expected a released request to be claimable, got %v", err)
+	}
+
+	req.ID = "../../escape"
+	if err := Claim(req, executor); err == nil {
+		t.Error("expected an invalid request ID to be rejected")
+	}
+}
@@ -0,0 +1,59 @@
+package approval
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/yourusername/iamctl/internal/config"
+)
+
+var requestIDPattern = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// Claim records that the request is being executed by executor, an AWS
+// caller ARN, so the same approval cannot run twice. It fails if the request
+// has already been claimed on this machine.
+func Claim(r *Request, executor string) error {
+	path, err := executedPath(r.ID)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			data, _ := os.ReadFile(path)
+			return fmt.Errorf("request %s has already been executed (%s)", r.ID, data)
+		}
+		return fmt.Errorf("failed to record execution: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "%s by %s", time.Now().UTC().Format(time.RFC3339), executor); err != nil {
+		return fmt.Errorf("failed to record execution: %w", err)
+	}
+	return nil
+}
+
+// Release forgets a claim whose execution failed, so the request can be
+// retried until it expires
+func Release(r *Request) error {
+	path, err := executedPath(r.ID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to release request %s: %w", r.ID, err)
+	}
+	return nil
+}
+
+// executedPath returns the marker file of a request ID
+func executedPath(id string) (string, error) {
+	if !requestIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid request ID %q", id)
+	}
+	return config.Path("approval", "executed", id)
+}
@@ -0,0 +1,203 @@
+package approval
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/yourusername/iamctl/internal/config"
+)
+
+var keyNamePattern = regexp.MustCompile(`^[A-Za-z0-9._@-]+$`)
+
+// Signer is an operator's named ed25519 signing key
+type Signer struct {
+	Name       string
+	PrivateKey ed25519.PrivateKey
+}
+
+// PublicKey is an operator's named ed25519 public key
+type PublicKey struct {
+	Name string `json:"name"`
+	Key  string `json:"public_key"`
+}
+
+type privateKeyFile struct {
+	Name string `json:"name"`
+	Seed string `json:"private_key"`
+}
+
+// GenerateKey creates a signing key for name and stores it under the iamctl
+// state directory. It returns the public key path so it can be shared with
+// other operators. The key is not trusted until imported with Trust, so one
+// operator cannot mint the two keys execute requires.
+func GenerateKey(name string) (string, error) {
+	if !keyNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid key name %q", name)
+	}
+
+	keyPath, err := config.Path("approval", "keys", name+".key")
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(keyPath); err == nil {
+		return "", fmt.Errorf("signing key %q already exists", name)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	data, err := json.MarshalIndent(privateKeyFile{
+		Name: name,
+		Seed: base64.StdEncoding.EncodeToString(priv.Seed()),
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(keyPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write signing key: %w", err)
+	}
+
+	public := PublicKey{Name: name, Key: base64.StdEncoding.EncodeToString(pub)}
+	pubPath := strings.TrimSuffix(keyPath, ".key") + ".pub"
+	if err := writePublicKey(pubPath, public); err != nil {
+		return "", err
+	}
+
+	return pubPath, nil
+}
+
+// LoadSigner loads the signing key stored for name
+func LoadSigner(name string) (*Signer, error) {
+	if !keyNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid key name %q", name)
+	}
+
+	keyPath, err := config.Path("approval", "keys", name+".key")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("signing key %q not found, create it with 'iamctl approve keygen %s'", name, name)
+		}
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	var stored privateKeyFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	seed, err := base64.StdEncoding.DecodeString(stored.Seed)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key %q is corrupt", name)
+	}
+
+	return &Signer{Name: stored.Name, PrivateKey: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// LocalKeys returns the public halves of every signing key stored on this
+// machine. Two signatures from local keys were made by the same operator.
+func LocalKeys() ([]PublicKey, error) {
+	dir, err := config.Path("approval", "keys", "")
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.key"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []PublicKey
+	for _, path := range paths {
+		signer, err := LoadSigner(strings.TrimSuffix(filepath.Base(path), ".key"))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, signer.Public())
+	}
+	return keys, nil
+}
+
+// Public returns the signer's public key
+func (s *Signer) Public() PublicKey {
+	return PublicKey{
+		Name: s.Name,
+		Key:  base64.StdEncoding.EncodeToString(s.PrivateKey.Public().(ed25519.PublicKey)),
+	}
+}
+
+// ReadPublicKey reads a public key file shared by another operator
+func ReadPublicKey(path string) (PublicKey, error) {
+	var public PublicKey
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return public, fmt.Errorf("failed to read public key: %w", err)
+	}
+	if err := json.Unmarshal(data, &public); err != nil {
+		return public, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	if !keyNamePattern.MatchString(public.Name) {
+		return public, fmt.Errorf("invalid key name %q", public.Name)
+	}
+	if key, err := base64.StdEncoding.DecodeString(public.Key); err != nil || len(key) != ed25519.PublicKeySize {
+		return public, fmt.Errorf("public key %q is corrupt", public.Name)
+	}
+
+	return public, nil
+}
+
+// Trust adds a public key to the set accepted by 'iamctl execute'
+func Trust(public PublicKey) error {
+	path, err := config.Path("approval", "trusted", public.Name+".pub")
+	if err != nil {
+		return err
+	}
+	return writePublicKey(path, public)
+}
+
+// TrustedKeys returns every trusted public key
+func TrustedKeys() ([]PublicKey, error) {
+	dir, err := config.Path("approval", "trusted", "")
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pub"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []PublicKey
+	for _, path := range paths {
+		public, err := ReadPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, public)
+	}
+	return keys, nil
+}
+
+func writePublicKey(path string, public PublicKey) error {
+	data, err := json.MarshalIndent(public, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+	return nil
+}
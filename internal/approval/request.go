@@ -0,0 +1,268 @@
+// Package approval implements the two-person approval workflow for
+// destructive operations. A request is signed by the operator who creates
+// it, countersigned by a second operator and only then executed.
+package approval
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Signature roles
+const (
+	RoleRequester = "requester"
+	RoleApprover  = "approver"
+)
+
+// Request is a destructive operation waiting for approval. Requester is the
+// AWS caller ARN of the operator who created it.
+type Request struct {
+	ID         string            `json:"id"`
+	Operation  string            `json:"operation"`
+	Parameters map[string]string `json:"parameters"`
+	Requester  string            `json:"requester"`
+	CreatedAt  time.Time         `json:"created_at"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+	Signatures []Signature       `json:"signatures"`
+}
+
+// Signature is one operator's signature over a request
+type Signature struct {
+	Role      string `json:"role"`
+	Signer    string `json:"signer"`
+	PublicKey string `json:"public_key"`
+	Value     string `json:"signature"`
+}
+
+// NewRequest creates a request signed by the requesting operator, whose AWS
+// caller ARN is requester
+func NewRequest(operation string, params map[string]string, signer *Signer, requester string, ttl time.Duration) (*Request, error) {
+	if _, ok := Lookup(operation); !ok {
+		return nil, fmt.Errorf("operation %q does not support approval", operation)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate request ID: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	req := &Request{
+		ID:         hex.EncodeToString(id),
+		Operation:  operation,
+		Parameters: params,
+		Requester:  requester,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	if err := req.sign(RoleRequester, signer); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Load reads a request file
+func Load(path string) (*Request, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request: %w", err)
+	}
+
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("failed to parse request: %w", err)
+	}
+	return &req, nil
+}
+
+// Save writes the request file
+func (r *Request) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write request: %w", err)
+	}
+	return nil
+}
+
+// Approve adds the approving operator's signature. The requester's
+// signature must be valid and the approver must be a different key.
+func (r *Request) Approve(signer *Signer) error {
+	if err := r.checkExpiry(); err != nil {
+		return err
+	}
+
+	requester, err := r.signature(RoleRequester)
+	if err != nil {
+		return err
+	}
+	if err := r.verifySignature(requester); err != nil {
+		return err
+	}
+	if requester.PublicKey == signer.Public().Key {
+		return errors.New("a request cannot be approved with the requester's own key")
+	}
+	if _, err := r.signature(RoleApprover); err == nil {
+		return errors.New("request has already been approved")
+	}
+
+	return r.sign(RoleApprover, signer)
+}
+
+// Verify checks that the request carries valid requester and approver
+// signatures from two different trusted keys and has not expired. local are
+// the signing keys held on this machine; both signatures coming from them
+// means one operator signed twice.
+func (r *Request) Verify(trusted, local []PublicKey) error {
+	if err := r.checkExpiry(); err != nil {
+		return err
+	}
+
+	requester, err := r.signature(RoleRequester)
+	if err != nil {
+		return err
+	}
+	approver, err := r.signature(RoleApprover)
+	if err != nil {
+		return errors.New("request has not been approved")
+	}
+	if requester.PublicKey == approver.PublicKey {
+		return errors.New("request was approved with the requester's own key")
+	}
+	if isLocal(requester, local) && isLocal(approver, local) {
+		return errors.New("request was signed twice with keys held by one operator")
+	}
+
+	for _, sig := range []Signature{requester, approver} {
+		if !isTrusted(sig, trusted) {
+			return fmt.Errorf("%s key %q is not trusted", sig.Role, sig.Signer)
+		}
+		if err := r.verifySignature(sig); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Summary describes the request for operators reviewing it
+func (r *Request) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Request:   %s\n", r.ID)
+	fmt.Fprintf(&b, "Operation: %s\n", r.Operation)
+
+	keys := make([]string, 0, len(r.Parameters))
+	for key := range r.Parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "  %s: %s\n", key, r.Parameters[key])
+	}
+
+	fmt.Fprintf(&b, "Requester: %s\n", r.Requester)
+	for _, sig := range r.Signatures {
+		if sig.Role == RoleRequester {
+			fmt.Fprintf(&b, "Signed by: %s\n", sig.Signer)
+		}
+	}
+	fmt.Fprintf(&b, "Expires:   %s\n", r.ExpiresAt.Local().Format("2006-01-02 15:04:05 MST"))
+	for _, sig := range r.Signatures {
+		if sig.Role == RoleApprover {
+			fmt.Fprintf(&b, "Approver:  %s\n", sig.Signer)
+		}
+	}
+	return b.String()
+}
+
+// payload is the signed portion of the request
+func (r *Request) payload() ([]byte, error) {
+	return json.Marshal(struct {
+		ID         string            `json:"id"`
+		Operation  string            `json:"operation"`
+		Parameters map[string]string `json:"parameters"`
+		Requester  string            `json:"requester"`
+		CreatedAt  time.Time         `json:"created_at"`
+		ExpiresAt  time.Time         `json:"expires_at"`
+	}{r.ID, r.Operation, r.Parameters, r.Requester, r.CreatedAt, r.ExpiresAt})
+}
+
+func (r *Request) sign(role string, signer *Signer) error {
+	payload, err := r.payload()
+	if err != nil {
+		return err
+	}
+
+	r.Signatures = append(r.Signatures, Signature{
+		Role:      role,
+		Signer:    signer.Name,
+		PublicKey: signer.Public().Key,
+		Value:     base64.StdEncoding.EncodeToString(ed25519.Sign(signer.PrivateKey, payload)),
+	})
+	return nil
+}
+
+func (r *Request) signature(role string) (Signature, error) {
+	for _, sig := range r.Signatures {
+		if sig.Role == role {
+			return sig, nil
+		}
+	}
+	return Signature{}, fmt.Errorf("request has no %s signature", role)
+}
+
+func (r *Request) verifySignature(sig Signature) error {
+	key, err := base64.StdEncoding.DecodeString(sig.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("%s public key is corrupt", sig.Role)
+	}
+	value, err := base64.StdEncoding.DecodeString(sig.Value)
+	if err != nil {
+		return fmt.Errorf("%s signature is corrupt", sig.Role)
+	}
+
+	payload, err := r.payload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), payload, value) {
+		return fmt.Errorf("%s signature is invalid", sig.Role)
+	}
+	return nil
+}
+
+func (r *Request) checkExpiry() error {
+	if time.Now().After(r.ExpiresAt) {
+		return fmt.Errorf("request expired at %s", r.ExpiresAt.Local().Format("2006-01-02 15:04:05 MST"))
+	}
+	return nil
+}
+
+func isTrusted(sig Signature, trusted []PublicKey) bool {
+	for _, key := range trusted {
+		if key.Key == sig.PublicKey && key.Name == sig.Signer {
+			return true
+		}
+	}
+	return false
+}
+
+func isLocal(sig Signature, local []PublicKey) bool {
+	for _, key := range local {
+		if key.Key == sig.PublicKey {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+// Package arn parses, builds and validates Amazon Resource Names. Generated
+// ARNs must use the caller's partition and account ID, which come from
+// sts:GetCallerIdentity; hard-coding either breaks every real account.
+package arn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported partitions
+const (
+	PartitionAWS      = "aws"
+	PartitionChina    = "aws-cn"
+	PartitionGovCloud = "aws-us-gov"
+)
+
+// Partitions lists the supported partitions
+var Partitions = []string{PartitionAWS, PartitionChina, PartitionGovCloud}
+
+// AWSAccount is the account field of AWS managed resources, such as
+// arn:aws:iam::aws:policy/ReadOnlyAccess
+const AWSAccount = "aws"
+
+// AnyAccount matches every account in policy resources
+const AnyAccount = "*"
+
+// ARN is a parsed Amazon Resource Name:
+// arn:partition:service:region:account-id:resource
+type ARN struct {
+	Partition string
+	Service   string
+	Region    string
+	AccountID string
+	Resource  string
+}
+
+// Parse splits and validates an ARN. The resource may itself contain colons.
+func Parse(s string) (ARN, error) {
+	parts := strings.SplitN(s, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" {
+		return ARN{}, fmt.Errorf("invalid ARN %q: expected arn:partition:service:region:account-id:resource", s)
+	}
+
+	a := ARN{
+		Partition: parts[1],
+		Service:   parts[2],
+		Region:    parts[3],
+		AccountID: parts[4],
+		Resource:  parts[5],
+	}
+	if err := a.Validate(); err != nil {
+		return ARN{}, fmt.Errorf("invalid ARN %q: %w", s, err)
+	}
+	return a, nil
+}
+
+// Build returns the ARN of resource, failing when any part is invalid
+func Build(partition, service, region, accountID, resource string) (string, error) {
+	a := ARN{Partition: partition, Service: service, Region: region, AccountID: accountID, Resource: resource}
+	if err := a.Validate(); err != nil {
+		return "", err
+	}
+	return a.String(), nil
+}
+
+// Validate checks the partition is supported, the service and resource are
+// set, and the account ID is empty, twelve digits, AWSAccount or AnyAccount
+func (a ARN) Validate() error {
+	if !ValidPartition(a.Partition) {
+		return fmt.Errorf("unsupported partition %q (want one of %s)", a.Partition, strings.Join(Partitions, ", "))
+	}
+	if a.Service == "" {
+		return fmt.Errorf("missing service")
+	}
+	if a.AccountID != "" && a.AccountID != AWSAccount && a.AccountID != AnyAccount && !isAccountID(a.AccountID) {
+		return fmt.Errorf("invalid account ID %q: expected 12 digits", a.AccountID)
+	}
+	if a.Resource == "" {
+		return fmt.Errorf("missing resource")
+	}
+	return nil
+}
+
+// String formats the ARN
+func (a ARN) String() string {
+	return strings.Join([]string{"arn", a.Partition, a.Service, a.Region, a.AccountID, a.Resource}, ":")
+}
+
+// ValidPartition reports whether partition is supported
+func ValidPartition(partition string) bool {
+	for _, p := range Partitions {
+		if p == partition {
+			return true
+		}
+	}
+	return false
+}
+
+// isAccountID reports whether s is a twelve digit account ID
+func isAccountID(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package arn
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ARN
+	}{
+		{"arn:aws:iam::123456789012:user/alice", ARN{"aws", "iam", "", "123456789012", "user/alice"}},
+		{"arn:aws-cn:iam::123456789012:policy/EnforceMFA", ARN{"aws-cn", "iam", "", "123456789012", "policy/EnforceMFA"}},
+		{"arn:aws-us-gov:sts::123456789012:assumed-role/Admin/bob", ARN{"aws-us-gov", "sts", "", "123456789012", "assumed-role/Admin/bob"}},
+		{"arn:aws:iam::aws:policy/ReadOnlyAccess", ARN{"aws", "iam", "", "aws", "policy/ReadOnlyAccess"}},
+		{"arn:aws:logs:eu-west-1:123456789012:log-group:/app:*", ARN{"aws", "logs", "eu-west-1", "123456789012", "log-group:/app:*"}},
+		{"arn:aws:s3:::bucket", ARN{"aws", "s3", "", "", "bucket"}},
+		{"arn:aws-cn:iam::*:user/${aws:username}", ARN{"aws-cn", "iam", "", "*", "user/${aws:username}"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if got.String() != tt.in {
+			t.Errorf("Parse(%q).String() = %q", tt.in, got.String())
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"invalid-arn",
+		"arn:aws:iam::123456789012",
+		"urn:aws:iam::123456789012:user/alice",
+		"arn:aws-iso:iam::123456789012:user/alice",
+		"arn:aws::123456789012:user/alice",
+		"arn:aws:iam::12345:user/alice",
+		"arn:aws:iam::12345678901x:user/alice",
+		"arn:aws:iam::123456789012:",
+	} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	for _, partition := range Partitions {
+		got, err := Build(partition, "iam", "", "123456789012", "policy/EnforceMFA")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "arn:" + partition + ":iam::123456789012:policy/EnforceMFA"; got != want {
+			t.Errorf("Build = %q, want %q", got, want)
+		}
+	}
+
+	if _, err := Build("aws", "iam", "", "", ""); err == nil {
+		t.Error("Expected a missing resource to fail")
+	}
+	if _, err := Build("", "iam", "", "123456789012", "policy/EnforceMFA"); err == nil {
+		t.Error("Expected a missing partition to fail")
+	}
+}
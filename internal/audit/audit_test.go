@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("IAMCTL_HOME", dir)
+
+	for _, target := range []string{"bob", "carol"} {
+		err := Record(Event{
+			Actor:   "arn:aws:iam::123456789012:user/helpdesk",
+			Action:  "mfa.provision",
+			Target:  target,
+			Details: map[string]string{"serial": "arn:aws:iam::123456789012:mfa/" + target},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(dir, "audit.log")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("audit log mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var targets []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+		if event.Time.IsZero() {
+			t.Error("event time was not stamped")
+		}
+		targets = append(targets, event.Target)
+	}
+	if len(targets) != 2 || targets[0] != "bob" || targets[1] != "carol" {
+		t.Errorf("recorded targets = %v, want [bob carol]", targets)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/yourusername/iamctl/internal/session"
 )
 
 // NewIAMClient creates a new IAM client using AWS SDK v2 patterns
@@ -15,6 +17,54 @@ func NewIAMClient(profile string) (*iam.Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
+	cfg, err := LoadConfig(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return iam.NewFromConfig(cfg), nil
+}
+
+// LoadConfig loads the shared AWS configuration for a profile. When an MFA
+// session started with 'iamctl session start' is cached for the profile's own
+// user, its credentials are used instead of the profile's own.
+func LoadConfig(ctx context.Context, profile string) (aws.Config, error) {
+	cfg, err := loadSharedConfig(ctx, profile)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	if entry := userSession(profile, ""); entry != nil {
+		return withSessionCredentials(cfg, entry), nil
+	}
+
+	return cfg, nil
+}
+
+// userSession returns the profile's cached session if it can stand in for
+// the profile's own credentials, or nil
+func userSession(profile, serial string) *session.Entry {
+	entry, err := session.Load(profile)
+	if err != nil || !usableSession(entry, serial) {
+		return nil
+	}
+	return entry
+}
+
+// usableSession reports whether a cached session can stand in for the
+// profile's user. Role sessions from 'session start --role-arn' act as the
+// role, so they only serve callers that ask for that role. A non-empty
+// serial must match the device the session was verified with.
+func usableSession(entry *session.Entry, serial string) bool {
+	if entry == nil || entry.RoleARN != "" {
+		return false
+	}
+	return serial == "" || entry.SerialNumber == serial
+}
+
+// loadSharedConfig loads the profile's configuration without consulting the
+// session cache
+func loadSharedConfig(ctx context.Context, profile string) (aws.Config, error) {
 	// Config options for v2
 	var opts []func(*config.LoadOptions) error
 
@@ -28,8 +78,8 @@ func NewIAMClient(profile string) (*iam.Client, error) {
 	// Load the AWS configuration
 	cfg, err := config.LoadDefaultConfig(ctx, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	return iam.NewFromConfig(cfg), nil
-}
\ No newline at end of file
+	return cfg, nil
+}
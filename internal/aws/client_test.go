@@ -3,6 +3,15 @@ package aws
 import (
 	"context"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/aws/smithy-go"
+	"github.com/yourusername/iamctl/internal/session"
 )
 
 func TestProfileSwitching(t *testing.T) {
@@ -40,4 +49,141 @@ func TestMFAOperations(t *testing.T) {
 	}
 	
 	t.Log("MFA function correctly handles parameters")
-}
\ No newline at end of file
+}
+
+type mockSTSClient struct {
+	getSessionTokenFunc   func(context.Context, *sts.GetSessionTokenInput) (*sts.GetSessionTokenOutput, error)
+	getCallerIdentityFunc func(context.Context, *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error)
+}
+
+func (m *mockSTSClient) GetCallerIdentity(ctx context.Context, input *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	return m.getCallerIdentityFunc(ctx, input)
+}
+
+func (m *mockSTSClient) GetSessionToken(ctx context.Context, input *sts.GetSessionTokenInput, optFns ...func(*sts.Options)) (*sts.GetSessionTokenOutput, error) {
+	return m.getSessionTokenFunc(ctx, input)
+}
+
+type mockMFALister struct {
+	serials []string
+}
+
+func (m *mockMFALister) ListMFADevices(ctx context.Context, input *iam.ListMFADevicesInput, optFns ...func(*iam.Options)) (*iam.ListMFADevicesOutput, error) {
+	output := &iam.ListMFADevicesOutput{}
+	for _, serial := range m.serials {
+		output.MFADevices = append(output.MFADevices, types.MFADevice{SerialNumber: aws.String(serial)})
+	}
+	return output, nil
+}
+
+func TestVerifyMFA(t *testing.T) {
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour)
+
+	client := &mockSTSClient{
+		getSessionTokenFunc: func(ctx context.Context, input *sts.GetSessionTokenInput) (*sts.GetSessionTokenOutput, error) {
+			if *input.TokenCode != "123456" {
+				return nil, &smithy.GenericAPIError{
+					Code:    "AccessDenied",
+					Message: "MultiFactorAuthentication failed with invalid MFA one time pass code.",
+				}
+			}
+			return &sts.GetSessionTokenOutput{
+				Credentials: &ststypes.Credentials{
+					AccessKeyId:     aws.String("ASIATEST"),
+					SecretAccessKey: aws.String("secret"),
+					SessionToken:    aws.String("token"),
+					Expiration:      aws.Time(expires),
+				},
+			}, nil
+		},
+	}
+
+	session, err := verifyMFAWithClient(ctx, client, aws.Config{}, "arn:aws:iam::123456789012:mfa/user", "123456", 0)
+	if err != nil {
+		t.Fatalf("expected verification to succeed, got %v", err)
+	}
+	creds, err := session.Config.Credentials.Retrieve(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "ASIATEST" || creds.SessionToken != "token" {
+		t.Errorf("expected session credentials on the verified config, got %+v", creds)
+	}
+
+	_, err = verifyMFAWithClient(ctx, client, aws.Config{}, "arn:aws:iam::123456789012:mfa/user", "000000", 0)
+	if _, ok := err.(*MFAError); !ok {
+		t.Errorf("expected MFAError for a rejected code, got %T", err)
+	}
+}
+
+func TestResolveMFASerial(t *testing.T) {
+	ctx := context.Background()
+
+	serial, err := ResolveMFASerial(ctx, &mockMFALister{serials: []string{
+		"arn:aws:iam::123456789012:u2f/user/alice/key",
+		"arn:aws:iam::123456789012:mfa/alice",
+	}}, "", "")
+	if err != nil || serial != "arn:aws:iam::123456789012:mfa/alice" {
+		t.Errorf("expected the only code-based device, got %q (%v)", serial, err)
+	}
+
+	_, err = ResolveMFASerial(ctx, &mockMFALister{serials: []string{
+		"arn:aws:iam::123456789012:mfa/phone",
+		"GAHT12345678",
+	}}, "", "")
+	if err == nil {
+		t.Error("expected an error when several devices are registered")
+	}
+
+	serial, err = ResolveMFASerial(ctx, &mockMFALister{}, "", "GAHT12345678")
+	if err != nil || serial != "GAHT12345678" {
+		t.Errorf("expected explicit serial to be returned, got %q (%v)", serial, err)
+	}
+}
+
+func TestUsableSession(t *testing.T) {
+	serial := "arn:aws:iam::123456789012:mfa/alice"
+	user := &session.Entry{Kind: session.KindSessionToken, SerialNumber: serial}
+	role := &session.Entry{Kind: session.KindAssumeRole, RoleARN: "arn:aws:iam::123456789012:role/Admin", SerialNumber: serial}
+
+	if !usableSession(user, "") || !usableSession(user, serial) {
+		t.Error("expected the user session to be reused")
+	}
+	if usableSession(user, "arn:aws:iam::123456789012:mfa/other") {
+		t.Error("expected a session verified with another device to be ignored")
+	}
+	if usableSession(role, "") {
+		t.Error("expected a role session not to stand in for the user")
+	}
+	if usableSession(nil, "") {
+		t.Error("expected no session to be unusable")
+	}
+}
+
+func TestCallerIdentity(t *testing.T) {
+	ctx := context.Background()
+
+	callerARN := "arn:aws-us-gov:sts::123456789012:assumed-role/Admin/alice"
+	client := &mockSTSClient{
+		getCallerIdentityFunc: func(ctx context.Context, input *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+			return &sts.GetCallerIdentityOutput{Arn: aws.String(callerARN), Account: aws.String("123456789012")}, nil
+		},
+	}
+	caller, err := CallerIdentity(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caller.Partition != "aws-us-gov" || caller.AccountID != "123456789012" {
+		t.Errorf("caller = %+v", caller)
+	}
+
+	client.getCallerIdentityFunc = func(ctx context.Context, input *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+		return nil, &smithy.GenericAPIError{Code: "InvalidClientTokenId", Message: "bad token"}
+	}
+	if _, err := CallerIdentity(ctx, client); err == nil {
+		t.Error("expected an error for invalid credentials")
+	} else if _, ok := err.(*CredentialError); !ok {
+		t.Errorf("expected CredentialError, got %T", err)
+	}
+}
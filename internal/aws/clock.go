@@ -0,0 +1,15 @@
+package aws
+
+import (
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ClockSkew reports how far AWS server time is ahead of the local clock, as
+// measured on a completed API response's metadata. A negative value means the
+// local clock is ahead.
+func ClockSkew(metadata middleware.Metadata) (time.Duration, bool) {
+	return awsmiddleware.GetAttemptSkew(metadata)
+}
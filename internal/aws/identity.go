@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/yourusername/iamctl/internal/arn"
+)
+
+// callerIdentityAPI is the subset of the STS client used to identify the
+// caller
+type callerIdentityAPI interface {
+	GetCallerIdentity(context.Context, *sts.GetCallerIdentityInput, ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// CallerIdentity returns the caller's ARN, whose partition and account ID
+// every ARN iamctl generates must use. It needs no IAM permissions.
+func CallerIdentity(ctx context.Context, client callerIdentityAPI) (arn.ARN, error) {
+	output, err := client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) {
+			switch ae.ErrorCode() {
+			case "UnrecognizedClientException", "InvalidClientTokenId", "ExpiredToken":
+				return arn.ARN{}, &CredentialError{Err: err}
+			}
+		}
+		return arn.ARN{}, &ServiceError{Err: err}
+	}
+
+	caller, err := arn.Parse(*output.Arn)
+	if err != nil {
+		return arn.ARN{}, &ServiceError{Err: err}
+	}
+	return caller, nil
+}
+
+// STSClient returns an STS client with the credentials and region of an IAM
+// client, for callers that were handed only the IAM client
+func STSClient(client *iam.Client) *sts.Client {
+	options := client.Options()
+	return sts.New(sts.Options{
+		Region:      options.Region,
+		Credentials: options.Credentials,
+		HTTPClient:  options.HTTPClient,
+		Logger:      options.Logger,
+		AppID:       options.AppID,
+	})
+}
@@ -2,38 +2,153 @@ package aws
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
-	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/yourusername/iamctl/internal/session"
 )
 
-// GetMFAEnabledClient creates a client with MFA token for sensitive operations
+// GetMFAEnabledClient creates a client with MFA token for sensitive operations.
+// The assumed-role credentials are cached for the profile, so later calls
+// reuse them until they expire.
 func GetMFAEnabledClient(ctx context.Context, baseProfile, roleARN, serialNumber, mfaToken string) (*iam.Client, error) {
-	// 1. Load base configuration
-	baseCfg, err := config.LoadDefaultConfig(ctx, config.WithSharedConfigProfile(baseProfile))
+	// 1. Reuse a cached session for the same role
+	if entry, err := session.Load(baseProfile); err == nil && entry != nil && entry.RoleARN == roleARN {
+		cfg, err := loadSharedConfig(ctx, baseProfile)
+		if err != nil {
+			return nil, err
+		}
+		return iam.NewFromConfig(withSessionCredentials(cfg, entry)), nil
+	}
+
+	// 2. Load base configuration
+	baseCfg, err := loadSharedConfig(ctx, baseProfile)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Assume the role with MFA and cache the result
+	entry, err := assumeRoleWithMFA(ctx, baseCfg, baseProfile, roleARN, serialNumber, mfaToken, time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	return iam.NewFromConfig(withSessionCredentials(baseCfg, entry)), nil
+}
+
+// StartSession obtains MFA-backed temporary credentials for profile, with
+// sts:GetSessionToken or, when roleARN is set, sts:AssumeRole, and caches
+// them encrypted on disk until they expire. As with RequireMFA, reading the
+// code is not timed; each AWS call gets its own deadline under ctx.
+func StartSession(ctx context.Context, profile, serial, roleARN string, duration time.Duration, readToken func(ctx context.Context) (string, error)) (*session.Entry, error) {
+	lookupCtx, cancelLookup := context.WithTimeout(ctx, apiTimeout)
+	defer cancelLookup()
+
+	cfg, err := loadSharedConfig(lookupCtx, profile)
+	if err != nil {
+		return nil, &CredentialError{Err: err}
+	}
+
+	serial, err = ResolveMFASerial(lookupCtx, iam.NewFromConfig(cfg), "", serial)
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. Create STS client from base configuration
+	token, err := readToken(ctx)
+	if err != nil {
+		return nil, &MFAError{Err: err}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+
+	if roleARN != "" {
+		return assumeRoleWithMFA(ctx, cfg, profile, roleARN, serial, token, duration)
+	}
+
+	verified, err := verifyMFAWithClient(ctx, sts.NewFromConfig(cfg), cfg, serial, token, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &session.Entry{
+		Profile:         session.ProfileName(profile),
+		Kind:            session.KindSessionToken,
+		SerialNumber:    serial,
+		AccessKeyID:     verified.Credentials.AccessKeyID,
+		SecretAccessKey: verified.Credentials.SecretAccessKey,
+		SessionToken:    verified.Credentials.SessionToken,
+		Expiration:      verified.Credentials.Expires,
+	}
+	if err := session.Save(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// CachedSession returns the profile's cached session when it matches roleARN,
+// and otherwise starts a new one with StartSession
+func CachedSession(ctx context.Context, profile, serial, roleARN string, duration time.Duration, readToken func(ctx context.Context) (string, error)) (*session.Entry, error) {
+	if entry, err := session.Load(profile); err == nil && entry != nil && entry.RoleARN == roleARN {
+		return entry, nil
+	}
+	return StartSession(ctx, profile, serial, roleARN, duration, readToken)
+}
+
+// assumeRoleWithMFA assumes roleARN with an MFA code and caches the session
+func assumeRoleWithMFA(ctx context.Context, baseCfg aws.Config, profile, roleARN, serial, token string, duration time.Duration) (*session.Entry, error) {
+	// Create STS client from base configuration
 	stsClient := sts.NewFromConfig(baseCfg)
 
-	// 3. Configure AssumeRole with MFA
-	creds := stscreds.NewAssumeRoleProvider(stsClient, roleARN,
+	// Configure AssumeRole with MFA
+	provider := stscreds.NewAssumeRoleProvider(stsClient, roleARN,
 		func(o *stscreds.AssumeRoleOptions) {
-			o.SerialNumber = aws.String(serialNumber)
+			o.SerialNumber = aws.String(serial)
+			o.Duration = duration
 			o.TokenProvider = func() (string, error) {
-				return mfaToken, nil
+				return token, nil
 			}
 		})
 
-	// 4. Create new configuration with MFA credentials
-	mfaCfg := aws.NewConfig()
-	*mfaCfg = baseCfg.Copy()
-	mfaCfg.Credentials = aws.NewCredentialsCache(creds)
+	creds, err := provider.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume role with MFA: %w", err)
+	}
+
+	entry := &session.Entry{
+		Profile:         session.ProfileName(profile),
+		Kind:            session.KindAssumeRole,
+		RoleARN:         roleARN,
+		SerialNumber:    serial,
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+		Expiration:      creds.Expires,
+	}
+	if err := session.Save(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
 
-	return iam.NewFromConfig(*mfaCfg), nil
-}
\ No newline at end of file
+// withSessionCredentials returns a copy of cfg that authenticates with the
+// cached session's credentials
+func withSessionCredentials(cfg aws.Config, entry *session.Entry) aws.Config {
+	sessionCfg := cfg.Copy()
+	sessionCfg.Credentials = aws.NewCredentialsCache(credentials.StaticCredentialsProvider{
+		Value: aws.Credentials{
+			AccessKeyID:     entry.AccessKeyID,
+			SecretAccessKey: entry.SecretAccessKey,
+			SessionToken:    entry.SessionToken,
+			Source:          "iamctl-session",
+			CanExpire:       true,
+			Expires:         entry.Expiration,
+		},
+	})
+	return sessionCfg
+}
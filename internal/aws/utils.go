@@ -40,4 +40,6 @@ type PermissionError struct{ Err error }
 func (e *PermissionError) Error() string { return "permission error: " + e.Err.Error() }
 
 type ServiceError struct{ Err error }
-func (e *ServiceError) Error() string { return "service error: " + e.Err.Error() }
\ No newline at end of file
+func (e *ServiceError) Error() string { return "service error: " + e.Err.Error() }
+type MFAError struct{ Err error }
+func (e *MFAError) Error() string { return "MFA error: " + e.Err.Error() }
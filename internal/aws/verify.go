@@ -0,0 +1,179 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+)
+
+// sessionTokenAPI is the subset of the STS client used to verify MFA codes
+type sessionTokenAPI interface {
+	GetSessionToken(context.Context, *sts.GetSessionTokenInput, ...func(*sts.Options)) (*sts.GetSessionTokenOutput, error)
+}
+
+// mfaDeviceLister is the subset of the IAM client used to find MFA devices
+type mfaDeviceLister interface {
+	ListMFADevices(context.Context, *iam.ListMFADevicesInput, ...func(*iam.Options)) (*iam.ListMFADevicesOutput, error)
+}
+
+// VerifiedSession holds temporary credentials obtained with a valid MFA code.
+// Clients built from it carry MFA context for the rest of the command.
+type VerifiedSession struct {
+	SerialNumber string
+	Credentials  aws.Credentials
+	Config       aws.Config
+}
+
+// IAMClient returns an IAM client authenticated with the verified session
+func (s *VerifiedSession) IAMClient() *iam.Client {
+	return iam.NewFromConfig(s.Config)
+}
+
+// apiTimeout bounds each AWS call RequireMFA makes
+const apiTimeout = 15 * time.Second
+
+// RequireMFA is the verification gate for sensitive commands. A cached
+// session from 'iamctl session start' for the profile's own user, verified
+// with serial when one is given, satisfies it without prompting.
+// Otherwise it resolves the caller's MFA device (unless serial is given),
+// reads a code with readToken and proves it with sts:GetSessionToken.
+//
+// Reading the code waits on a human, so it is not timed: ctx should carry
+// no deadline, and each AWS call gets its own. Callers start their API
+// deadline after RequireMFA returns.
+func RequireMFA(ctx context.Context, profile, serial string, readToken func(ctx context.Context) (string, error)) (*VerifiedSession, error) {
+	apiCtx, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+
+	cfg, err := loadSharedConfig(apiCtx, profile)
+	if err != nil {
+		return nil, &CredentialError{Err: err}
+	}
+
+	if entry := userSession(profile, serial); entry != nil {
+		sessionCfg := withSessionCredentials(cfg, entry)
+		creds, err := sessionCfg.Credentials.Retrieve(apiCtx)
+		if err != nil {
+			return nil, &CredentialError{Err: err}
+		}
+		return &VerifiedSession{SerialNumber: entry.SerialNumber, Credentials: creds, Config: sessionCfg}, nil
+	}
+
+	serial, err = ResolveMFASerial(apiCtx, iam.NewFromConfig(cfg), "", serial)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := readToken(ctx)
+	if err != nil {
+		return nil, &MFAError{Err: err}
+	}
+
+	verifyCtx, cancelVerify := context.WithTimeout(ctx, apiTimeout)
+	defer cancelVerify()
+	return VerifyMFA(verifyCtx, cfg, serial, token)
+}
+
+// VerifyMFA proves possession of a current MFA code for serial and returns
+// a session built on the resulting temporary credentials
+func VerifyMFA(ctx context.Context, cfg aws.Config, serial, token string) (*VerifiedSession, error) {
+	return verifyMFAWithClient(ctx, sts.NewFromConfig(cfg), cfg, serial, token, 0)
+}
+
+// verifyMFAWithClient calls GetSessionToken; a zero duration uses the STS
+// default session length
+func verifyMFAWithClient(ctx context.Context, client sessionTokenAPI, cfg aws.Config, serial, token string, duration time.Duration) (*VerifiedSession, error) {
+	if serial == "" {
+		return nil, &MFAError{Err: errors.New("no MFA device serial number")}
+	}
+	if token == "" {
+		return nil, &MFAError{Err: errors.New("no MFA code")}
+	}
+
+	input := &sts.GetSessionTokenInput{
+		SerialNumber: aws.String(serial),
+		TokenCode:    aws.String(token),
+	}
+	if duration > 0 {
+		input.DurationSeconds = aws.Int32(int32(duration.Seconds()))
+	}
+
+	output, err := client.GetSessionToken(ctx, input)
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) {
+			switch ae.ErrorCode() {
+			case "AccessDenied", "AccessDeniedException":
+				// STS reports rejected MFA codes as AccessDenied
+				if strings.Contains(ae.ErrorMessage(), "MultiFactorAuthentication") {
+					return nil, &MFAError{Err: err}
+				}
+				return nil, &PermissionError{Err: err}
+			case "UnrecognizedClientException", "InvalidClientTokenId":
+				return nil, &CredentialError{Err: err}
+			}
+		}
+		return nil, &ServiceError{Err: err}
+	}
+
+	creds := aws.Credentials{
+		AccessKeyID:     aws.ToString(output.Credentials.AccessKeyId),
+		SecretAccessKey: aws.ToString(output.Credentials.SecretAccessKey),
+		SessionToken:    aws.ToString(output.Credentials.SessionToken),
+		Source:          "iamctl-mfa",
+		CanExpire:       true,
+		Expires:         aws.ToTime(output.Credentials.Expiration),
+	}
+
+	sessionCfg := cfg.Copy()
+	sessionCfg.Credentials = aws.NewCredentialsCache(credentials.StaticCredentialsProvider{Value: creds})
+
+	return &VerifiedSession{
+		SerialNumber: serial,
+		Credentials:  creds,
+		Config:       sessionCfg,
+	}, nil
+}
+
+// ResolveMFASerial returns serial if set, otherwise the user's only
+// code-based MFA device. FIDO security keys cannot be used with STS and are
+// ignored. An empty username means the caller.
+func ResolveMFASerial(ctx context.Context, client mfaDeviceLister, username, serial string) (string, error) {
+	if serial != "" {
+		return serial, nil
+	}
+
+	input := &iam.ListMFADevicesInput{}
+	if username != "" {
+		input.UserName = aws.String(username)
+	}
+
+	output, err := client.ListMFADevices(ctx, input)
+	if err != nil {
+		return "", &ServiceError{Err: err}
+	}
+
+	var serials []string
+	for _, device := range output.MFADevices {
+		if s := aws.ToString(device.SerialNumber); !strings.Contains(s, ":u2f/") {
+			serials = append(serials, s)
+		}
+	}
+
+	switch len(serials) {
+	case 0:
+		return "", &MFAError{Err: errors.New("no code-based MFA device is registered; enable one with 'iamctl mfa enable'")}
+	case 1:
+		return serials[0], nil
+	default:
+		return "", &MFAError{Err: fmt.Errorf("%d MFA devices are registered; choose one with --mfa-serial", len(serials))}
+	}
+}
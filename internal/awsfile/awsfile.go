@@ -0,0 +1,218 @@
+// Package awsfile reads and edits the AWS shared credentials and config files
+// in place, preserving comments, ordering and unrelated settings.
+package awsfile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// CredentialsPath returns the shared credentials file location, honouring
+// AWS_SHARED_CREDENTIALS_FILE like the AWS CLI does
+func CredentialsPath() string {
+	if path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); path != "" {
+		return path
+	}
+	return config.DefaultSharedCredentialsFilename()
+}
+
+// ConfigPath returns the shared config file location, honouring AWS_CONFIG_FILE
+func ConfigPath() string {
+	if path := os.Getenv("AWS_CONFIG_FILE"); path != "" {
+		return path
+	}
+	return config.DefaultSharedConfigFilename()
+}
+
+// File is an INI-style AWS file kept as raw lines so edits only touch the
+// keys that change
+type File struct {
+	path  string
+	mode  os.FileMode
+	lines []string
+}
+
+// Load reads the file at path. A missing file yields an empty File that will
+// be created on Save.
+func Load(path string) (*File, error) {
+	f := &File{path: path, mode: 0600}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return f, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		f.mode = info.Mode().Perm()
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		f.lines = append(f.lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// Path returns the location the file was loaded from
+func (f *File) Path() string {
+	return f.path
+}
+
+// Sections returns section names in file order
+func (f *File) Sections() []string {
+	var sections []string
+	for _, line := range f.lines {
+		if name, ok := sectionName(line); ok {
+			sections = append(sections, name)
+		}
+	}
+	return sections
+}
+
+// Section returns the key/value pairs of a section, or nil if it is absent
+func (f *File) Section(name string) map[string]string {
+	start, end := f.sectionBounds(name)
+	if start < 0 {
+		return nil
+	}
+
+	values := make(map[string]string)
+	for _, line := range f.lines[start+1 : end] {
+		if key, value, ok := keyValue(line); ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// Get returns a single value from a section
+func (f *File) Get(section, key string) (string, bool) {
+	value, ok := f.Section(section)[key]
+	return value, ok
+}
+
+// Set updates key in section, appending the key or the section when missing
+func (f *File) Set(section, key, value string) {
+	entry := fmt.Sprintf("%s = %s", key, value)
+
+	start, end := f.sectionBounds(section)
+	if start < 0 {
+		if len(f.lines) > 0 && strings.TrimSpace(f.lines[len(f.lines)-1]) != "" {
+			f.lines = append(f.lines, "")
+		}
+		f.lines = append(f.lines, "["+section+"]", entry)
+		return
+	}
+
+	for i := start + 1; i < end; i++ {
+		if k, _, ok := keyValue(f.lines[i]); ok && k == key {
+			f.lines[i] = entry
+			return
+		}
+	}
+
+	// Insert after the last non-blank line of the section
+	insert := end
+	for insert > start+1 && strings.TrimSpace(f.lines[insert-1]) == "" {
+		insert--
+	}
+	f.lines = append(f.lines[:insert], append([]string{entry}, f.lines[insert:]...)...)
+}
+
+// Save writes the file atomically, keeping its original permissions (0600
+// for new files)
+func (f *File) Save() error {
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(f.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	content := strings.Join(f.lines, "\n") + "\n"
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+	if err := tmp.Chmod(f.mode); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set permissions on %s: %w", f.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// sectionBounds returns the header line index of section and the index just
+// past its last line, or -1 when the section does not exist
+func (f *File) sectionBounds(section string) (int, int) {
+	start := -1
+	for i, line := range f.lines {
+		name, ok := sectionName(line)
+		if !ok {
+			continue
+		}
+		if start >= 0 {
+			return start, i
+		}
+		if name == section {
+			start = i
+		}
+	}
+	if start < 0 {
+		return -1, -1
+	}
+	return start, len(f.lines)
+}
+
+func sectionName(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) < 2 || trimmed[0] != '[' || trimmed[len(trimmed)-1] != ']' {
+		return "", false
+	}
+	return strings.TrimSpace(trimmed[1 : len(trimmed)-1]), true
+}
+
+func keyValue(line string) (string, string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || trimmed[0] == '#' || trimmed[0] == ';' {
+		return "", "", false
+	}
+	key, value, ok := strings.Cut(trimmed, "=")
+	if !ok {
+		return "", "", false
+	}
+	return strings.TrimSpace(key), strings.TrimSpace(value), true
+}
+
+// ProfileSection returns the config file section name for a profile:
+// "default" or "profile <name>"
+func ProfileSection(profile string) string {
+	if profile == "" || profile == "default" {
+		return "default"
+	}
+	return "profile " + profile
+}
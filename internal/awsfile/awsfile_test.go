@@ -0,0 +1,99 @@
+package awsfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testCredentials = `# managed by hand
+[default]
+aws_access_key_id = AKIADEFAULT
+aws_secret_access_key = default-secret
+
+[temp]
+aws_access_key_id = ASIATEMP
+aws_secret_access_key = temp-secret
+aws_session_token = token
+
+[work]
+; work account
+aws_access_key_id=AKIAWORK
+aws_secret_access_key=work-secret
+`
+
+func TestStaticKeyProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(path, []byte(testCredentials), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profiles := f.StaticKeyProfiles()
+	if len(profiles) != 2 {
+		t.Fatalf("expected 2 static profiles, got %d", len(profiles))
+	}
+	if profiles[0].Name != "default" || profiles[1].Name != "work" {
+		t.Errorf("unexpected profiles: %+v", profiles)
+	}
+	if profiles[1].AccessKeyID != "AKIAWORK" {
+		t.Errorf("expected AKIAWORK, got %s", profiles[1].AccessKeyID)
+	}
+}
+
+func TestSetPreservesLayout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(path, []byte(testCredentials), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetStaticKey("work", "AKIANEW", "new-secret")
+	f.Set("default", "region", "eu-west-1")
+	f.Set("extra", "region", "us-east-1")
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `# managed by hand
+[default]
+aws_access_key_id = AKIADEFAULT
+aws_secret_access_key = default-secret
+region = eu-west-1
+
+[temp]
+aws_access_key_id = ASIATEMP
+aws_secret_access_key = temp-secret
+aws_session_token = token
+
+[work]
+; work account
+aws_access_key_id = AKIANEW
+aws_secret_access_key = new-secret
+
+[extra]
+region = us-east-1
+`
+	if string(data) != expected {
+		t.Errorf("unexpected file content:\n%s", data)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %o", info.Mode().Perm())
+	}
+}
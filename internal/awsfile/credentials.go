@@ -0,0 +1,39 @@
+package awsfile
+
+import "strings"
+
+// StaticProfile is a credentials file profile holding a long-term access key
+type StaticProfile struct {
+	Name            string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// StaticKeyProfiles returns every profile with a long-term key pair. Profiles
+// carrying a session token hold temporary credentials and are skipped.
+func (f *File) StaticKeyProfiles() []StaticProfile {
+	var profiles []StaticProfile
+	for _, name := range f.Sections() {
+		values := f.Section(name)
+		keyID := values["aws_access_key_id"]
+		secret := values["aws_secret_access_key"]
+		if keyID == "" || secret == "" || values["aws_session_token"] != "" {
+			continue
+		}
+		if strings.HasPrefix(keyID, "ASIA") {
+			continue
+		}
+		profiles = append(profiles, StaticProfile{
+			Name:            name,
+			AccessKeyID:     keyID,
+			SecretAccessKey: secret,
+		})
+	}
+	return profiles
+}
+
+// SetStaticKey replaces the key pair stored for a profile
+func (f *File) SetStaticKey(profile, accessKeyID, secretAccessKey string) {
+	f.Set(profile, "aws_access_key_id", accessKeyID)
+	f.Set(profile, "aws_secret_access_key", secretAccessKey)
+}
@@ -0,0 +1,36 @@
+// Package config locates iamctl's local state directory
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Dir returns the iamctl state directory, ~/.iamctl unless IAMCTL_HOME is set
+func Dir() (string, error) {
+	if dir := os.Getenv("IAMCTL_HOME"); dir != "" {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".iamctl"), nil
+}
+
+// Path returns a path inside the state directory, creating its parent
+// directories with owner-only permissions
+func Path(elem ...string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(append([]string{dir}, elem...)...)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	return path, nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadSettings(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("IAMCTL_HOME", dir)
+
+	// Defaults without a config file
+	settings, err := LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.MFA.WarnAfterDays != DefaultMFAWarnAfterDays || settings.MFA.FailAfterDays != DefaultMFAFailAfterDays {
+		t.Errorf("defaults = %+v", settings.MFA)
+	}
+
+	// Values from the file, with unset values defaulted
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("mfa:\n  fail_after_days: 180\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	settings, err = LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.MFA.WarnAfterDays != DefaultMFAWarnAfterDays || settings.MFA.FailAfterDays != 180 {
+		t.Errorf("settings = %+v", settings.MFA)
+	}
+	if settings.Passwords.HIBPFile != "" || settings.Passwords.HIBPMinCount != DefaultHIBPMinCount {
+		t.Errorf("password settings = %+v", settings.Passwords)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("passwords:\n  hibp_file: /data/pwned.txt\n  hibp_min_count: 10\n  min_score: 3\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	settings, err = LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Passwords.HIBPFile != "/data/pwned.txt" || settings.Passwords.HIBPMinCount != 10 || settings.Passwords.MinScore != 3 {
+		t.Errorf("password settings = %+v", settings.Passwords)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("enforce:\n  mfa_exempt_actions: [iam:ListMFADevices, sts:GetSessionToken]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	settings, err = LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(settings.Enforce.MFAExemptActions, ",") != "iam:ListMFADevices,sts:GetSessionToken" {
+		t.Errorf("enforce settings = %+v", settings.Enforce)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("mfa: [\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSettings(); err == nil {
+		t.Error("expected an error for a malformed config file")
+	}
+}
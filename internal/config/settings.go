@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default MFA device age thresholds in days
+const (
+	DefaultMFAWarnAfterDays = 75
+	DefaultMFAFailAfterDays = 90
+)
+
+// DefaultHIBPMinCount rejects any password found in the breach list
+const DefaultHIBPMinCount = 1
+
+// Settings are the user's iamctl defaults from config.yaml in the state
+// directory. Command-line flags take precedence over them.
+type Settings struct {
+	MFA       MFASettings      `yaml:"mfa"`
+	Passwords PasswordSettings `yaml:"passwords"`
+	Enforce   EnforceSettings  `yaml:"enforce"`
+}
+
+// MFASettings hold the MFA device age thresholds shared by mfa status and
+// the account-wide reports
+type MFASettings struct {
+	WarnAfterDays int `yaml:"warn_after_days"`
+	FailAfterDays int `yaml:"fail_after_days"`
+}
+
+// PasswordSettings configure the checks password reset and password change
+// make beyond the account policy. The breached-password check is off while
+// HIBPFile is empty and the strength check while MinScore is 0.
+type PasswordSettings struct {
+	HIBPFile     string `yaml:"hibp_file"`
+	HIBPMinCount int    `yaml:"hibp_min_count"`
+	MinScore     int    `yaml:"min_score"`
+}
+
+// EnforceSettings configure the policies enforce attaches. MFAExemptActions
+// replaces the actions enforce mfa allows without MFA; empty keeps the
+// built-in self-service list.
+type EnforceSettings struct {
+	MFAExemptActions []string `yaml:"mfa_exempt_actions"`
+}
+
+// LoadSettings reads config.yaml, filling unset values with defaults. A
+// missing file yields the defaults.
+func LoadSettings() (*Settings, error) {
+	settings := &Settings{}
+
+	path, err := Path("config.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, settings); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+
+	if settings.MFA.WarnAfterDays == 0 {
+		settings.MFA.WarnAfterDays = DefaultMFAWarnAfterDays
+	}
+	if settings.MFA.FailAfterDays == 0 {
+		settings.MFA.FailAfterDays = DefaultMFAFailAfterDays
+	}
+	if settings.Passwords.HIBPMinCount == 0 {
+		settings.Passwords.HIBPMinCount = DefaultHIBPMinCount
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,47 @@
+// Package executor runs per-item IAM calls concurrently with a bounded
+// number of workers, for commands that walk every user in an account
+package executor
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultWorkers keeps concurrent IAM calls below the API's throttling limits
+const DefaultWorkers = 8
+
+// Map calls fn for every item using at most workers goroutines and returns
+// the results and errors in item order. Items not yet started when ctx is
+// cancelled get ctx.Err() as their error.
+func Map[T, R any](ctx context.Context, items []T, workers int, fn func(context.Context, T) (R, error)) ([]R, []error) {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(items); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				results[i], errs[i] = fn(ctx, items[i])
+			}
+		}()
+	}
+
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results, errs
+}
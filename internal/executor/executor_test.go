@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMap(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	var running, peak int32
+	results, errs := Map(context.Background(), items, 3, func(ctx context.Context, n int) (int, error) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		if n == 4 {
+			return 0, errors.New("boom")
+		}
+		return n * n, nil
+	})
+
+	if peak > 3 {
+		t.Errorf("ran %d calls concurrently, want at most 3", peak)
+	}
+	for i, n := range items {
+		if n == 4 {
+			if errs[i] == nil {
+				t.Errorf("item %d: expected an error", n)
+			}
+			continue
+		}
+		if errs[i] != nil || results[i] != n*n {
+			t.Errorf("item %d: got %d, %v", n, results[i], errs[i])
+		}
+	}
+}
+
+func TestMapCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, errs := Map(ctx, []string{"a", "b"}, 1, func(ctx context.Context, s string) (string, error) {
+		return s, nil
+	})
+	for _, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want context.Canceled", err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Package exitcode lets commands choose the process exit status, so CI
+// pipelines can tell findings apart from failures
+package exitcode
+
+import "errors"
+
+// Exit codes
+const (
+	Failure   = 1 // the command could not complete
+	Violation = 2 // the command completed and found policy violations
+	Warning   = 3 // the command completed and found only warnings
+)
+
+// Error carries an exit code along with the error message
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New wraps err so the process exits with code
+func New(code int, err error) error {
+	return &Error{Code: code, Err: err}
+}
+
+// FromError returns the exit code for err: 0 for nil, the wrapped code for
+// an *Error and Failure otherwise
+func FromError(err error) int {
+	if err == nil {
+		return 0
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return Failure
+}
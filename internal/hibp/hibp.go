@@ -0,0 +1,165 @@
+// Package hibp checks passwords against a local copy of the Have I Been
+// Pwned password list, so breached passwords can be refused offline.
+//
+// The list must be the SHA-1 version ordered by hash, one HASH:COUNT line
+// per password. Lookups binary search the file with ReadAt, so files of
+// tens of gigabytes are searched in a few dozen reads without being loaded.
+package hibp
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// hashLen is the length of a hex-encoded SHA-1 hash
+const hashLen = sha1.Size * 2
+
+// chunkSize is read at a time while looking for line boundaries. It holds
+// a whole HASH:COUNT line.
+const chunkSize = 128
+
+// List is a sorted hash list opened for lookups
+type List struct {
+	r    io.ReaderAt
+	size int64
+	c    io.Closer
+}
+
+// Open opens the hash list at path
+func Open(path string) (*List, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &List{r: f, size: info.Size(), c: f}, nil
+}
+
+// New returns a list reading size bytes from r
+func New(r io.ReaderAt, size int64) *List {
+	return &List{r: r, size: size}
+}
+
+// Close closes the underlying file
+func (l *List) Close() error {
+	if l.c == nil {
+		return nil
+	}
+	return l.c.Close()
+}
+
+// Count returns how many times password appears in known breaches, or 0
+// when it is not in the list
+func (l *List) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	return l.CountHash(strings.ToUpper(hex.EncodeToString(sum[:])))
+}
+
+// CountHash is Count for an upper-case hex SHA-1 hash
+func (l *List) CountHash(hash string) (int, error) {
+	if len(hash) != hashLen {
+		return 0, fmt.Errorf("invalid SHA-1 hash %q", hash)
+	}
+	target := []byte(hash)
+
+	// Invariant: lo is the start of a line and the match, if any, starts
+	// in [lo, hi)
+	lo, hi := int64(0), l.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, err := l.lineStart(mid)
+		if err != nil {
+			return 0, err
+		}
+		if start >= hi {
+			// No line starts in [mid, hi)
+			hi = mid
+			continue
+		}
+
+		line, next, err := l.readLine(start)
+		if err != nil {
+			return 0, err
+		}
+		if len(line) < hashLen {
+			return 0, fmt.Errorf("malformed line at offset %d", start)
+		}
+
+		switch cmp := bytes.Compare(target, bytes.ToUpper(line[:hashLen])); {
+		case cmp == 0:
+			return parseCount(line, start)
+		case cmp < 0:
+			hi = mid
+		default:
+			lo = next
+		}
+	}
+	return 0, nil
+}
+
+// lineStart returns the offset of the first line starting at or after off
+func (l *List) lineStart(off int64) (int64, error) {
+	if off == 0 {
+		return 0, nil
+	}
+
+	// A line starts at off when the byte before it ends the previous line
+	buf := make([]byte, chunkSize)
+	for pos := off - 1; pos < l.size; pos += chunkSize {
+		n, err := l.r.ReadAt(buf, pos)
+		if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+			return pos + int64(i) + 1, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+	}
+	return l.size, nil
+}
+
+// readLine returns the line starting at off without its line ending, and
+// the offset of the next line
+func (l *List) readLine(off int64) ([]byte, int64, error) {
+	buf := make([]byte, chunkSize)
+	n, err := l.r.ReadAt(buf, off)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, 0, err
+	}
+	buf = buf[:n]
+
+	next := off + int64(n)
+	if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+		buf, next = buf[:i], off+int64(i)+1
+	} else if next < l.size {
+		return nil, 0, fmt.Errorf("line at offset %d is too long", off)
+	}
+	return bytes.TrimRight(buf, "\r"), next, nil
+}
+
+// parseCount reads the breach count after the hash
+func parseCount(line []byte, off int64) (int, error) {
+	rest := line[hashLen:]
+	if len(rest) == 0 {
+		// Lists without counts still mark the password as breached
+		return 1, nil
+	}
+	if rest[0] != ':' {
+		return 0, fmt.Errorf("malformed line at offset %d", off)
+	}
+	count, err := strconv.Atoi(string(rest[1:]))
+	if err != nil {
+		return 0, fmt.Errorf("malformed count at offset %d: %w", off, err)
+	}
+	return count, nil
+}
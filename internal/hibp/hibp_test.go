@@ -0,0 +1,97 @@
+package hibp
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// buildList returns a sorted list of the given passwords and counts
+func buildList(counts map[string]int, lineEnding string) string {
+	var lines []string
+	for password, count := range counts {
+		lines = append(lines, fmt.Sprintf("%s:%d", sha1Hex(password), count))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, lineEnding) + lineEnding
+}
+
+func TestCount(t *testing.T) {
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		counts[fmt.Sprintf("password%d", i)] = i + 1
+	}
+
+	for _, lineEnding := range []string{"\n", "\r\n"} {
+		content := buildList(counts, lineEnding)
+		list := New(strings.NewReader(content), int64(len(content)))
+
+		for password, want := range counts {
+			got, err := list.Count(password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Fatalf("Count(%q) = %d, want %d", password, got, want)
+			}
+		}
+
+		for _, password := range []string{"", "correct horse battery staple", "password1000"} {
+			got, err := list.Count(password)
+			if err != nil || got != 0 {
+				t.Errorf("Count(%q) = %d, %v, want 0", password, got, err)
+			}
+		}
+	}
+}
+
+func TestCountFirstAndLastLine(t *testing.T) {
+	// No trailing newline and lower-case hashes are accepted
+	content := strings.ToLower(sha1Hex("a")) + ":7\n" + sha1Hex("b") + ":9"
+	if sha1Hex("a") > sha1Hex("b") {
+		content = sha1Hex("b") + ":9\n" + strings.ToLower(sha1Hex("a")) + ":7"
+	}
+	list := New(strings.NewReader(content), int64(len(content)))
+
+	if got, err := list.Count("a"); err != nil || got != 7 {
+		t.Errorf("Count(a) = %d, %v", got, err)
+	}
+	if got, err := list.Count("b"); err != nil || got != 9 {
+		t.Errorf("Count(b) = %d, %v", got, err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+	if err := os.WriteFile(path, []byte(buildList(map[string]int{"hunter2": 17043}, "\r\n")), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer list.Close()
+
+	if got, err := list.Count("hunter2"); err != nil || got != 17043 {
+		t.Errorf("Count(hunter2) = %d, %v", got, err)
+	}
+}
+
+func TestMalformedList(t *testing.T) {
+	content := "not a hash list\n"
+	list := New(strings.NewReader(content), int64(len(content)))
+	if _, err := list.Count("password"); err == nil {
+		t.Error("Expected a malformed list to fail")
+	}
+}
@@ -0,0 +1,27 @@
+// Package keyring keeps small secrets in the operating system's keyring: the
+// login Keychain on macOS, the Secret Service (through secret-tool) on Linux
+// and the Credential Manager on Windows. Secrets stored there are protected
+// by the user's login rather than by file permissions alone.
+package keyring
+
+import "errors"
+
+var (
+	// ErrNotFound is returned by Get when no secret is stored
+	ErrNotFound = errors.New("secret not found in the OS keyring")
+
+	// ErrUnavailable is returned when the platform has no usable keyring
+	ErrUnavailable = errors.New("no OS keyring is available")
+)
+
+// Store reads and writes secrets by service and account
+type Store interface {
+	Get(service, account string) (string, error)
+	Set(service, account, secret string) error
+	Delete(service, account string) error
+}
+
+// System returns the operating system's keyring
+func System() Store {
+	return systemStore{}
+}
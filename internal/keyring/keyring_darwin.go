@@ -0,0 +1,68 @@
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errItemNotFound is the exit status of security(1) for a missing item
+const errItemNotFound = 44
+
+// systemStore talks to the login Keychain through security(1)
+type systemStore struct{}
+
+// Get implements Store
+func (systemStore) Get(service, account string) (string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command("/usr/bin/security", "find-generic-password", "-s", service, "-a", account, "-w")
+	cmd.Stdout = &stdout
+	if err := run(cmd); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Set implements Store. The command is written to 'security -i' on stdin so
+// the secret never appears in the process list.
+func (systemStore) Set(service, account, secret string) error {
+	for _, value := range []string{service, account, secret} {
+		if strings.ContainsAny(value, "\"\\\n") {
+			return errors.New("keychain values cannot contain quotes, backslashes or newlines")
+		}
+	}
+
+	cmd := exec.Command("/usr/bin/security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s \"%s\" -a \"%s\" -w \"%s\"\n", service, account, secret))
+	return run(cmd)
+}
+
+// Delete implements Store
+func (systemStore) Delete(service, account string) error {
+	err := run(exec.Command("/usr/bin/security", "delete-generic-password", "-s", service, "-a", account))
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// run runs a security(1) command, mapping a missing item to ErrNotFound
+func run(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == errItemNotFound:
+		return ErrNotFound
+	case errors.As(err, &exitErr):
+		return fmt.Errorf("keychain: %s", strings.TrimSpace(stderr.String()))
+	default:
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+}
@@ -0,0 +1,68 @@
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// systemStore talks to the Secret Service (GNOME Keyring, KWallet and
+// others) through libsecret's secret-tool
+type systemStore struct{}
+
+// Get implements Store
+func (systemStore) Get(service, account string) (string, error) {
+	stdout, err := secretTool("", "lookup", "service", service, "account", account)
+	if err != nil {
+		return "", err
+	}
+	if stdout == "" {
+		return "", ErrNotFound
+	}
+	return stdout, nil
+}
+
+// Set implements Store. The secret is passed on stdin, never as an argument.
+func (systemStore) Set(service, account, secret string) error {
+	_, err := secretTool(secret, "store", "--label="+service+" "+account, "service", service, "account", account)
+	return err
+}
+
+// Delete implements Store
+func (systemStore) Delete(service, account string) error {
+	_, err := secretTool("", "clear", "service", service, "account", account)
+	return err
+}
+
+// secretTool runs secret-tool and returns its trimmed stdout. A lookup that
+// finds nothing exits 1 silently; any message on stderr means the Secret
+// Service itself could not be reached.
+func secretTool(stdin string, args ...string) (string, error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return "", fmt.Errorf("%w: install secret-tool (libsecret-tools)", ErrUnavailable)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", err
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%w: %s", ErrUnavailable, message)
+		}
+		if args[0] == "lookup" {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("secret-tool %s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,21 @@
+//go:build !darwin && !linux && !windows
+
+package keyring
+
+// systemStore reports that this platform has no supported keyring
+type systemStore struct{}
+
+// Get implements Store
+func (systemStore) Get(service, account string) (string, error) {
+	return "", ErrUnavailable
+}
+
+// Set implements Store
+func (systemStore) Set(service, account, secret string) error {
+	return ErrUnavailable
+}
+
+// Delete implements Store
+func (systemStore) Delete(service, account string) error {
+	return ErrUnavailable
+}
@@ -0,0 +1,108 @@
+// Package mfatoken obtains MFA codes for the verification gate. Codes come
+// from the first configured source, such as an external mfa_process command
+// set on the profile, with the interactive prompt as the fallback.
+package mfatoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/yourusername/iamctl/internal/awsfile"
+	"github.com/yourusername/iamctl/internal/prompt"
+)
+
+// ErrNotConfigured is returned by a source that is not set up for the
+// profile, so the next source is tried silently
+var ErrNotConfigured = errors.New("not configured")
+
+// codePattern matches a six-digit TOTP code
+var codePattern = regexp.MustCompile(`^[0-9]{6}$`)
+
+// Source produces an MFA code
+type Source interface {
+	Name() string
+	Token(ctx context.Context) (string, error)
+}
+
+// PromptSource reads a code interactively
+type PromptSource struct {
+	Read func() (string, error)
+}
+
+// Name implements Source
+func (p PromptSource) Name() string {
+	return "prompt"
+}
+
+// Token implements Source
+func (p PromptSource) Token(ctx context.Context) (string, error) {
+	return p.Read()
+}
+
+// Chain returns a token reader that tries each source in order. A source
+// that fails is reported on stderr and the next one is tried. The reader's
+// ctx is passed to every source, so cancelling it stops an mfa_process.
+func Chain(sources ...Source) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		var errs []error
+		for _, source := range sources {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			token, err := source.Token(ctx)
+			if errors.Is(err, ErrNotConfigured) {
+				continue
+			}
+			if err == nil {
+				err = Validate(token)
+			}
+			if err == nil {
+				return token, nil
+			}
+
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+			fmt.Fprintf(os.Stderr, "Warning: MFA code from %s failed: %v\n", source.Name(), err)
+		}
+		if len(errs) == 0 {
+			return "", errors.New("no MFA code source is configured")
+		}
+		return "", errors.Join(errs...)
+	}
+}
+
+// ForProfile returns the token reader for a profile: a code given with
+// --mfa-code or IAMCTL_MFA_CODE, else its mfa_process, then its
+// mfa_seed_file from the AWS config file if set, then read
+func ForProfile(profile string, read func() (string, error)) func(ctx context.Context) (string, error) {
+	if code, ok := prompt.ExplicitMFACode(); ok {
+		return Chain(PromptSource{Read: func() (string, error) { return code, nil }})
+	}
+
+	var sources []Source
+	if settings, err := profileSettings(profile); err == nil {
+		sources = append(sources,
+			NewProcessSource(settings),
+			SeedFileSource{Path: settings["mfa_seed_file"]})
+	}
+	return Chain(append(sources, PromptSource{Read: read})...)
+}
+
+// Validate checks that token looks like an MFA code
+func Validate(token string) error {
+	if !codePattern.MatchString(token) {
+		return errors.New("MFA code must be six digits")
+	}
+	return nil
+}
+
+// profileSettings returns the profile's section of the AWS config file
+func profileSettings(profile string) (map[string]string, error) {
+	configFile, err := awsfile.Load(awsfile.ConfigPath())
+	if err != nil {
+		return nil, err
+	}
+	return configFile.Section(awsfile.ProfileSection(profile)), nil
+}
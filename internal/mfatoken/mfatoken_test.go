@@ -0,0 +1,198 @@
+package mfatoken
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/yourusername/iamctl/internal/seedbox"
+	"github.com/yourusername/iamctl/internal/totp"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"ykman oath accounts code -s aws", []string{"ykman", "oath", "accounts", "code", "-s", "aws"}},
+		{`op item get "AWS prod" --otp`, []string{"op", "item", "get", "AWS prod", "--otp"}},
+		{`echo 'a b' c\ d`, []string{"echo", "a b", "c d"}},
+		{`printf ""`, []string{"printf", ""}},
+	}
+
+	for _, tc := range tests {
+		got, err := splitCommand(tc.command)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("splitCommand(%q) = %q, %v; want %q", tc.command, got, err, tc.want)
+		}
+	}
+
+	if _, err := splitCommand(`echo "unterminated`); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+}
+
+func TestProcessSource(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+
+	script := filepath.Join(t.TempDir(), "code.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"aws  $1\"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewProcessSource(map[string]string{"mfa_process": script + " 123456"})
+	token, err := source.Token(context.Background())
+	if err != nil || token != "123456" {
+		t.Errorf("Token() = %q, %v; want 123456", token, err)
+	}
+
+	slow := NewProcessSource(map[string]string{"mfa_process": "sleep 5", "mfa_process_timeout": "100ms"})
+	start := time.Now()
+	if _, err := slow.Token(context.Background()); err == nil || time.Since(start) > 2*time.Second {
+		t.Errorf("expected a timeout error, got %v after %s", err, time.Since(start))
+	}
+
+	if _, err := NewProcessSource(nil).Token(context.Background()); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("expected ErrNotConfigured without mfa_process, got %v", err)
+	}
+}
+
+type fixedSource struct {
+	token string
+	err   error
+	calls *int
+}
+
+func (f fixedSource) Name() string { return "fixed" }
+
+func (f fixedSource) Token(ctx context.Context) (string, error) {
+	*f.calls++
+	return f.token, f.err
+}
+
+func TestChain(t *testing.T) {
+	var calls int
+	prompt := PromptSource{Read: func() (string, error) { return "654321", nil }}
+
+	// Unconfigured sources are skipped
+	token, err := Chain(fixedSource{err: ErrNotConfigured, calls: &calls}, prompt)(context.Background())
+	if err != nil || token != "654321" {
+		t.Errorf("Chain() = %q, %v; want the prompt's code", token, err)
+	}
+
+	// A configured source wins over the prompt
+	token, err = Chain(fixedSource{token: "123456", calls: &calls}, prompt)(context.Background())
+	if err != nil || token != "123456" {
+		t.Errorf("Chain() = %q, %v; want 123456", token, err)
+	}
+
+	// Invalid output falls back to the prompt
+	token, err = Chain(fixedSource{token: "not-a-code", calls: &calls}, prompt)(context.Background())
+	if err != nil || token != "654321" {
+		t.Errorf("Chain() = %q, %v; want the prompt's code", token, err)
+	}
+
+	// Invalid codes from the last source are rejected
+	if _, err := Chain(PromptSource{Read: func() (string, error) { return "12345", nil }})(context.Background()); err == nil {
+		t.Error("expected a five-digit code to be rejected")
+	}
+
+	if calls != 3 {
+		t.Errorf("sources called %d times, want 3", calls)
+	}
+}
+
+func TestChainCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+
+	// Cancelling the reader's context stops a running mfa_process
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var calls int
+	read := Chain(NewProcessSource(map[string]string{"mfa_process": "sleep 5"}), fixedSource{token: "123456", calls: &calls})
+	start := time.Now()
+	if _, err := read(ctx); err == nil || time.Since(start) > 2*time.Second {
+		t.Errorf("expected the cancelled helper to fail fast, got %v after %s", err, time.Since(start))
+	}
+	if calls != 0 {
+		t.Errorf("sources after cancellation called %d times, want 0", calls)
+	}
+}
+
+func TestForProfile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses echo")
+	}
+
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv("AWS_CONFIG_FILE", path)
+	config := "[default]\nregion = eu-west-1\n\n[profile dev]\nmfa_process = echo 111111\n"
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	prompt := func() (string, error) { return "222222", nil }
+
+	if token, err := ForProfile("dev", prompt)(context.Background()); err != nil || token != "111111" {
+		t.Errorf("dev token = %q, %v; want 111111 from mfa_process", token, err)
+	}
+	if token, err := ForProfile("", prompt)(context.Background()); err != nil || token != "222222" {
+		t.Errorf("default token = %q, %v; want 222222 from the prompt", token, err)
+	}
+}
+
+func TestSeedFileSource(t *testing.T) {
+	t.Setenv("IAMCTL_HOME", t.TempDir())
+	dir := t.TempDir()
+
+	pubPath := filepath.Join(dir, "breakglass.pub")
+	if err := seedbox.GenerateKey("breakglass", pubPath); err != nil {
+		t.Fatal(err)
+	}
+	public, err := seedbox.ReadPublicKey(pubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seed := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	sealed, err := seedbox.Seal(public, seedbox.ContentSeed, []byte(seed+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealedPath := filepath.Join(dir, "breakglass.seed")
+	if err := os.WriteFile(sealedPath, sealed, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := SeedFileSource{Path: sealedPath}.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, _ := totp.DecodeSecret(seed)
+	if ok, _ := totp.Validate(secret, token, time.Now(), totp.Config{Skew: 1}); !ok {
+		t.Errorf("generated code %s does not validate", token)
+	}
+
+	// Plaintext seeds are refused
+	plainPath := filepath.Join(dir, "plain.seed")
+	if err := os.WriteFile(plainPath, []byte(seed+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (SeedFileSource{Path: plainPath}).Token(context.Background()); err == nil {
+		t.Error("expected a plaintext seed file to be refused")
+	}
+
+	if _, err := (SeedFileSource{}).Token(context.Background()); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("expected ErrNotConfigured without a path, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package mfatoken
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultProcessTimeout leaves time to touch a hardware key
+const defaultProcessTimeout = 30 * time.Second
+
+// ProcessSource runs an external command, such as
+// 'ykman oath accounts code -s aws', and uses its output as the code
+type ProcessSource struct {
+	Command string
+	Timeout time.Duration
+}
+
+// NewProcessSource configures a ProcessSource from a profile's mfa_process
+// and optional mfa_process_timeout settings
+func NewProcessSource(settings map[string]string) Source {
+	source := ProcessSource{
+		Command: settings["mfa_process"],
+		Timeout: defaultProcessTimeout,
+	}
+	if timeout, err := time.ParseDuration(settings["mfa_process_timeout"]); err == nil && timeout > 0 {
+		source.Timeout = timeout
+	}
+	return source
+}
+
+// Name implements Source
+func (p ProcessSource) Name() string {
+	return "mfa_process"
+}
+
+// Token implements Source. The command runs without a shell; its stderr and
+// stdin stay attached so it can ask for a touch or a password.
+func (p ProcessSource) Token(ctx context.Context) (string, error) {
+	if strings.TrimSpace(p.Command) == "" {
+		return "", ErrNotConfigured
+	}
+
+	args, err := splitCommand(p.Command)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("timed out after %s", p.Timeout)
+		}
+		return "", err
+	}
+
+	// Tools print the code on the last line, sometimes after a label
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) == 0 {
+		return "", errors.New("command printed no code")
+	}
+	return fields[len(fields)-1], nil
+}
+
+// splitCommand splits a command line into arguments, honouring single and
+// double quotes and backslash escapes like a POSIX shell
+func splitCommand(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape in mfa_process")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 {
+		return nil, ErrNotConfigured
+	}
+	return args, nil
+}
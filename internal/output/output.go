@@ -0,0 +1,77 @@
+// Package output renders command results as text tables, CSV or JSON so
+// reports share one set of --output formats
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats accepted by --output
+const (
+	FormatText = "text"
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Table is the tabular form of a result, used by the text and CSV renderers
+type Table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// Tabular is implemented by results that can be rendered. The JSON renderer
+// marshals the value itself, so structured fields keep their types.
+type Tabular interface {
+	Table() Table
+}
+
+// ValidateFormat checks an --output value; empty means text
+func ValidateFormat(format string) error {
+	switch format {
+	case "", FormatText, FormatCSV, FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q (use text, csv or json)", format)
+	}
+}
+
+// Render writes v to w in the given format
+func Render(w io.Writer, format string, v Tabular) error {
+	switch format {
+	case "", FormatText:
+		return renderText(w, v.Table())
+	case FormatCSV:
+		return renderCSV(w, v.Table())
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	default:
+		return ValidateFormat(format)
+	}
+}
+
+func renderText(w io.Writer, table Table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(table.Headers, "\t"))
+	for _, row := range table.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func renderCSV(w io.Writer, table Table) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(table.Headers); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	if err := writer.WriteAll(table.Rows); err != nil {
+		return fmt.Errorf("failed to write CSV record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+type testResult []struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func (r testResult) Table() Table {
+	return Table{
+		Headers: []string{"NAME", "COUNT"},
+		Rows:    [][]string{{"alice", "1"}, {"bob, jr", "22"}},
+	}
+}
+
+func TestRender(t *testing.T) {
+	result := testResult{{"alice", 1}, {"bob, jr", 22}}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatText, "NAME     COUNT\nalice    1\nbob, jr  22\n"},
+		{FormatCSV, "NAME,COUNT\nalice,1\n\"bob, jr\",22\n"},
+		{FormatJSON, "[\n  {\n    \"name\": \"alice\",\n    \"count\": 1\n  },\n  {\n    \"name\": \"bob, jr\",\n    \"count\": 22\n  }\n]\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Render(&buf, tc.format, result); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.want {
+				t.Errorf("Render(%s) = %q, want %q", tc.format, buf.String(), tc.want)
+			}
+		})
+	}
+
+	if err := Render(&bytes.Buffer{}, "yaml", result); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
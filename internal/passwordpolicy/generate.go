@@ -0,0 +1,97 @@
+package passwordpolicy
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DefaultGeneratedLength is used when the policy asks for fewer characters
+const DefaultGeneratedLength = 20
+
+// Ambiguous are characters easily confused with one another when a password
+// is read aloud or copied by hand
+const Ambiguous = "0Oo1lI|'"
+
+// Character classes drawn on by Generate
+const (
+	uppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	lowercase = "abcdefghijklmnopqrstuvwxyz"
+	numbers   = "0123456789"
+)
+
+// Generate returns a random password of the given length that satisfies the
+// policy, using crypto/rand. A length of 0 picks the larger of
+// DefaultGeneratedLength and the policy minimum. Every character class is
+// used, so the result meets any combination of class requirements.
+func (p Policy) Generate(length int, excludeAmbiguous bool) (string, error) {
+	if length == 0 {
+		length = max(DefaultGeneratedLength, p.MinimumLength)
+	}
+	if length < p.MinimumLength || length < MinAllowedLength || length > MaxLength {
+		return "", fmt.Errorf("length must be between %d and %d", max(p.MinimumLength, MinAllowedLength), MaxLength)
+	}
+
+	classes := []string{uppercase, lowercase, numbers, Symbols}
+	if excludeAmbiguous {
+		for i, class := range classes {
+			classes[i] = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(Ambiguous, r) {
+					return -1
+				}
+				return r
+			}, class)
+		}
+	}
+	all := strings.Join(classes, "")
+
+	// One character from each class, then the rest from all of them
+	password := make([]byte, 0, length)
+	for _, class := range classes {
+		c, err := randomChar(class)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	for len(password) < length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// Shuffle so the guaranteed characters are not always first
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	if violations := p.Validate(string(password)); len(violations) > 0 {
+		return "", fmt.Errorf("generated password does not meet the %s: %v", p.Describe(), violations)
+	}
+	return string(password), nil
+}
+
+// randomChar picks a character from set uniformly
+func randomChar(set string) (byte, error) {
+	i, err := randomInt(len(set))
+	if err != nil {
+		return 0, err
+	}
+	return set[i], nil
+}
+
+// randomInt returns a uniform random integer in [0, n)
+func randomInt(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read random data: %w", err)
+	}
+	return int(i.Int64()), nil
+}
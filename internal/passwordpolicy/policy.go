@@ -0,0 +1,197 @@
+// Package passwordpolicy checks passwords against the account's IAM password
+// policy, falling back to iamctl's built-in rules when the account has none
+package passwordpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// Symbols is the set of non-alphanumeric characters IAM counts as symbols
+const Symbols = "!@#$%^&*()_+-=[]{}|'"
+
+// Limits IAM places on every password
+const (
+	MaxLength        = 128
+	MinAllowedLength = 6
+)
+
+// Policy sources
+const (
+	SourceAccount  = "account"
+	SourceFallback = "built-in fallback"
+)
+
+// Policy is a set of password rules
+type Policy struct {
+	MinimumLength    int  `yaml:"minimum_length" json:"minimum_length"`
+	RequireUppercase bool `yaml:"require_uppercase" json:"require_uppercase"`
+	RequireLowercase bool `yaml:"require_lowercase" json:"require_lowercase"`
+	RequireNumbers   bool `yaml:"require_numbers" json:"require_numbers"`
+	RequireSymbols   bool `yaml:"require_symbols" json:"require_symbols"`
+	// MinCharacterClasses is only used by the fallback rules, which ask for
+	// any 3 of the 4 character classes rather than specific ones
+	MinCharacterClasses int `yaml:"-" json:"-"`
+	// The remaining rules are enforced by IAM and cannot be checked locally
+	PasswordReusePrevention    int    `yaml:"password_reuse_prevention" json:"password_reuse_prevention"`
+	MaxPasswordAge             int    `yaml:"max_password_age" json:"max_password_age"`
+	AllowUsersToChangePassword bool   `yaml:"allow_users_to_change_password" json:"allow_users_to_change_password"`
+	HardExpiry                 bool   `yaml:"hard_expiry" json:"hard_expiry"`
+	Source                     string `yaml:"-" json:"source"`
+}
+
+// Violation is an unmet rule, identified by a stable name
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
+
+// Default returns the built-in rules used when the account has no password
+// policy: at least 14 characters from 3 of the 4 character classes
+func Default() Policy {
+	return Policy{
+		MinimumLength:       14,
+		MinCharacterClasses: 3,
+		Source:              SourceFallback,
+	}
+}
+
+// FromAWS converts an IAM password policy
+func FromAWS(p *types.PasswordPolicy) Policy {
+	return Policy{
+		MinimumLength:              int(aws.ToInt32(p.MinimumPasswordLength)),
+		RequireUppercase:           p.RequireUppercaseCharacters,
+		RequireLowercase:           p.RequireLowercaseCharacters,
+		RequireNumbers:             p.RequireNumbers,
+		RequireSymbols:             p.RequireSymbols,
+		PasswordReusePrevention:    int(aws.ToInt32(p.PasswordReusePrevention)),
+		MaxPasswordAge:             int(aws.ToInt32(p.MaxPasswordAge)),
+		AllowUsersToChangePassword: p.AllowUsersToChangePassword,
+		HardExpiry:                 aws.ToBool(p.HardExpiry),
+		Source:                     SourceAccount,
+	}
+}
+
+// policyAPI is the subset of the IAM client used to read the policy
+type policyAPI interface {
+	GetAccountPasswordPolicy(context.Context, *iam.GetAccountPasswordPolicyInput, ...func(*iam.Options)) (*iam.GetAccountPasswordPolicyOutput, error)
+}
+
+// Load returns the account's password policy, or Default when the account
+// has none
+func Load(ctx context.Context, client policyAPI) (Policy, error) {
+	output, err := client.GetAccountPasswordPolicy(ctx, &iam.GetAccountPasswordPolicyInput{})
+	if err != nil {
+		var noSuchEntity *types.NoSuchEntityException
+		if errors.As(err, &noSuchEntity) {
+			return Default(), nil
+		}
+		return Policy{}, fmt.Errorf("failed to get account password policy: %w", err)
+	}
+	return FromAWS(output.PasswordPolicy), nil
+}
+
+// Validate returns every rule the password does not meet
+func (p Policy) Validate(password string) []Violation {
+	var violations []Violation
+
+	length := len([]rune(password))
+	minimum := p.MinimumLength
+	if minimum < MinAllowedLength {
+		minimum = MinAllowedLength
+	}
+	if length < minimum {
+		violations = append(violations, Violation{"minimum-length", fmt.Sprintf("must be at least %d characters (got %d)", minimum, length)})
+	}
+	if length > MaxLength {
+		violations = append(violations, Violation{"maximum-length", fmt.Sprintf("must be at most %d characters (got %d)", MaxLength, length)})
+	}
+
+	var hasUpper, hasLower, hasNumber, hasSymbol, hasInvalid bool
+	for _, r := range password {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			hasUpper = true
+		case r >= 'a' && r <= 'z':
+			hasLower = true
+		case r >= '0' && r <= '9':
+			hasNumber = true
+		case strings.ContainsRune(Symbols, r):
+			hasSymbol = true
+		case r < ' ' || r > '~':
+			// Only printable ASCII is accepted by IAM
+			hasInvalid = true
+		}
+	}
+
+	if hasInvalid {
+		violations = append(violations, Violation{"allowed-characters", "may only contain printable ASCII characters"})
+	}
+	if p.RequireUppercase && !hasUpper {
+		violations = append(violations, Violation{"require-uppercase", "must contain an uppercase letter (A-Z)"})
+	}
+	if p.RequireLowercase && !hasLower {
+		violations = append(violations, Violation{"require-lowercase", "must contain a lowercase letter (a-z)"})
+	}
+	if p.RequireNumbers && !hasNumber {
+		violations = append(violations, Violation{"require-numbers", "must contain a number (0-9)"})
+	}
+	if p.RequireSymbols && !hasSymbol {
+		violations = append(violations, Violation{"require-symbols", "must contain a symbol (" + Symbols + ")"})
+	}
+
+	if p.MinCharacterClasses > 0 {
+		classes := 0
+		for _, has := range []bool{hasUpper, hasLower, hasNumber, hasSymbol} {
+			if has {
+				classes++
+			}
+		}
+		if classes < p.MinCharacterClasses {
+			violations = append(violations, Violation{"character-classes", fmt.Sprintf("must use at least %d of: uppercase, lowercase, numbers, symbols (got %d)", p.MinCharacterClasses, classes)})
+		}
+	}
+
+	return violations
+}
+
+// Notices describes rules IAM enforces that cannot be checked locally
+func (p Policy) Notices() []string {
+	var notices []string
+	if p.PasswordReusePrevention > 0 {
+		notices = append(notices, fmt.Sprintf("password-reuse-prevention: IAM will reject any of the last %d passwords", p.PasswordReusePrevention))
+	}
+	return notices
+}
+
+// Describe returns a one-line summary of the policy
+func (p Policy) Describe() string {
+	rules := []string{fmt.Sprintf("at least %d characters", p.MinimumLength)}
+	for _, rule := range []struct {
+		required bool
+		name     string
+	}{
+		{p.RequireUppercase, "uppercase"},
+		{p.RequireLowercase, "lowercase"},
+		{p.RequireNumbers, "numbers"},
+		{p.RequireSymbols, "symbols"},
+	} {
+		if rule.required {
+			rules = append(rules, rule.name)
+		}
+	}
+	if p.MinCharacterClasses > 0 {
+		rules = append(rules, fmt.Sprintf("%d of 4 character classes", p.MinCharacterClasses))
+	}
+	return fmt.Sprintf("%s policy: %s", p.Source, strings.Join(rules, ", "))
+}
@@ -0,0 +1,219 @@
+package passwordpolicy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+type mockPolicyAPI struct {
+	getAccountPasswordPolicyFunc func(context.Context, *iam.GetAccountPasswordPolicyInput) (*iam.GetAccountPasswordPolicyOutput, error)
+}
+
+func (m *mockPolicyAPI) GetAccountPasswordPolicy(ctx context.Context, input *iam.GetAccountPasswordPolicyInput, optFns ...func(*iam.Options)) (*iam.GetAccountPasswordPolicyOutput, error) {
+	return m.getAccountPasswordPolicyFunc(ctx, input)
+}
+
+func rules(violations []Violation) map[string]bool {
+	names := map[string]bool{}
+	for _, v := range violations {
+		names[v.Rule] = true
+	}
+	return names
+}
+
+func TestLoad(t *testing.T) {
+	client := &mockPolicyAPI{
+		getAccountPasswordPolicyFunc: func(ctx context.Context, input *iam.GetAccountPasswordPolicyInput) (*iam.GetAccountPasswordPolicyOutput, error) {
+			return &iam.GetAccountPasswordPolicyOutput{PasswordPolicy: &types.PasswordPolicy{
+				MinimumPasswordLength:      aws.Int32(20),
+				RequireSymbols:             true,
+				RequireNumbers:             true,
+				RequireUppercaseCharacters: true,
+				RequireLowercaseCharacters: true,
+				PasswordReusePrevention:    aws.Int32(24),
+			}}, nil
+		},
+	}
+
+	policy, err := Load(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Source != SourceAccount || policy.MinimumLength != 20 || policy.PasswordReusePrevention != 24 || policy.MinCharacterClasses != 0 {
+		t.Errorf("policy = %+v", policy)
+	}
+	if len(policy.Notices()) != 1 {
+		t.Errorf("Expected a reuse-prevention notice, got %v", policy.Notices())
+	}
+}
+
+func TestLoadFallsBackWithoutAccountPolicy(t *testing.T) {
+	client := &mockPolicyAPI{
+		getAccountPasswordPolicyFunc: func(ctx context.Context, input *iam.GetAccountPasswordPolicyInput) (*iam.GetAccountPasswordPolicyOutput, error) {
+			return nil, &types.NoSuchEntityException{Message: aws.String("no policy")}
+		},
+	}
+
+	policy, err := Load(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy != Default() {
+		t.Errorf("Expected the fallback policy, got %+v", policy)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	strict := Policy{
+		MinimumLength:    12,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireNumbers:   true,
+		RequireSymbols:   true,
+	}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		password string
+		want     []string
+	}{
+		{"meets strict policy", strict, "Correct-Horse-9", nil},
+		{"every rule", strict, "short", []string{"minimum-length", "require-uppercase", "require-numbers", "require-symbols"}},
+		{"non-ASCII is not a symbol", strict, "Passwordé12345", []string{"allowed-characters", "require-symbols"}},
+		{"unlisted ASCII symbol is allowed", strict, "Password~12345!", nil},
+		{"fallback classes", Default(), "onlylowercaseandnumbers123", []string{"character-classes"}},
+		{"fallback length", Default(), "Short1!", []string{"minimum-length"}},
+		{"fallback valid", Default(), "ValidPass123!@", nil},
+		{"IAM minimum length", Policy{}, "abc", []string{"minimum-length"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules(tt.policy.Validate(tt.password))
+			if len(got) != len(tt.want) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+			for _, rule := range tt.want {
+				if !got[rule] {
+					t.Errorf("Validate(%q) missing %s, got %v", tt.password, rule, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPresets(t *testing.T) {
+	for _, name := range PresetNames() {
+		policy, err := Preset(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := policy.Check(); err != nil {
+			t.Errorf("preset %s is not a valid IAM policy: %v", name, err)
+		}
+	}
+
+	if _, err := Preset("unknown"); err == nil {
+		t.Error("Expected an unknown preset to fail")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	content := "minimum_length: 16\nrequire_symbols: true\npassword_reuse_prevention: 12\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.MinimumLength != 16 || !policy.RequireSymbols || policy.PasswordReusePrevention != 12 {
+		t.Errorf("policy = %+v", policy)
+	}
+
+	// Misspelt keys are rejected rather than silently ignored
+	if err := os.WriteFile(path, []byte("minimum_lenght: 16\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("Expected an unknown key to fail")
+	}
+
+	// Values IAM would reject fail before any call is made
+	if err := os.WriteFile(path, []byte("minimum_length: 16\npassword_reuse_prevention: 30\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("Expected an out of range reuse prevention to fail")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	current := Policy{MinimumLength: 14, RequireSymbols: true, AllowUsersToChangePassword: true, Source: SourceAccount}
+	desired, _ := Preset("nist")
+
+	changes := Diff(current, desired)
+	want := []Change{
+		{Setting: "minimum_length", Current: "14", Desired: "15"},
+		{Setting: "require_symbols", Current: "true", Desired: "false"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Diff = %+v, want %+v", changes, want)
+	}
+
+	// Without an account policy every setting is a change
+	if got := Diff(Default(), desired); len(got) != len(desired.Settings()) {
+		t.Errorf("Diff from fallback = %+v", got)
+	}
+}
+
+func TestUpdateInput(t *testing.T) {
+	input := Policy{MinimumLength: 15}.UpdateInput()
+	if aws.ToInt32(input.MinimumPasswordLength) != 15 {
+		t.Errorf("MinimumPasswordLength = %d", aws.ToInt32(input.MinimumPasswordLength))
+	}
+	// IAM rejects zero for these, so they are left unset to disable them
+	if input.PasswordReusePrevention != nil || input.MaxPasswordAge != nil {
+		t.Errorf("Expected disabled settings to be unset, got %+v", input)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	strict := Policy{MinimumLength: 24, RequireUppercase: true, RequireLowercase: true, RequireNumbers: true, RequireSymbols: true}
+
+	for _, policy := range []Policy{strict, Default(), {}} {
+		for i := 0; i < 50; i++ {
+			password, err := policy.Generate(0, i%2 == 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if violations := policy.Validate(password); len(violations) > 0 {
+				t.Fatalf("Generate() = %q violates %v", password, violations)
+			}
+			if len(password) != max(DefaultGeneratedLength, policy.MinimumLength) {
+				t.Errorf("len(Generate()) = %d", len(password))
+			}
+			if i%2 == 0 && strings.ContainsAny(password, Ambiguous) {
+				t.Errorf("Generate() = %q contains ambiguous characters", password)
+			}
+		}
+	}
+
+	if password, err := strict.Generate(40, false); err != nil || len(password) != 40 {
+		t.Errorf("Generate(40) = %q, %v", password, err)
+	}
+	if _, err := strict.Generate(16, false); err == nil {
+		t.Error("Expected a length below the policy minimum to fail")
+	}
+}
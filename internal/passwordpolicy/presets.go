@@ -0,0 +1,161 @@
+package passwordpolicy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"gopkg.in/yaml.v3"
+)
+
+// Limits IAM places on account password policy settings
+const (
+	MaxReusePrevention = 24
+	MaxPasswordAgeDays = 1095
+)
+
+// presets are named policies that can be applied with 'password policy set'
+var presets = map[string]Policy{
+	// CIS AWS Foundations Benchmark: 14 characters, no reuse of the last 24
+	// passwords, and the character class and 90 day expiry rules from the
+	// earlier benchmark versions
+	"cis": {
+		MinimumLength:              14,
+		RequireUppercase:           true,
+		RequireLowercase:           true,
+		RequireNumbers:             true,
+		RequireSymbols:             true,
+		PasswordReusePrevention:    24,
+		MaxPasswordAge:             90,
+		AllowUsersToChangePassword: true,
+	},
+	// NIST SP 800-63B: favour length over composition rules and only force
+	// a change when a password is known to be compromised
+	"nist": {
+		MinimumLength:              15,
+		AllowUsersToChangePassword: true,
+	},
+}
+
+// PresetNames returns the names of the built-in presets, sorted
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Preset returns the built-in policy with the given name
+func Preset(name string) (Policy, error) {
+	policy, ok := presets[name]
+	if !ok {
+		return Policy{}, fmt.Errorf("unknown preset %q (available: %v)", name, PresetNames())
+	}
+	policy.Source = name + " preset"
+	return policy, nil
+}
+
+// LoadFile reads a policy from a YAML file using the same keys as the JSON
+// output of 'password policy show'
+func LoadFile(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	var policy Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil {
+		return Policy{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	policy.Source = path
+	return policy, policy.Check()
+}
+
+// Check reports settings IAM would reject
+func (p Policy) Check() error {
+	switch {
+	case p.MinimumLength < MinAllowedLength || p.MinimumLength > MaxLength:
+		return fmt.Errorf("minimum length must be between %d and %d", MinAllowedLength, MaxLength)
+	case p.PasswordReusePrevention < 0 || p.PasswordReusePrevention > MaxReusePrevention:
+		return fmt.Errorf("password reuse prevention must be between 0 and %d", MaxReusePrevention)
+	case p.MaxPasswordAge < 0 || p.MaxPasswordAge > MaxPasswordAgeDays:
+		return fmt.Errorf("maximum password age must be between 0 and %d days", MaxPasswordAgeDays)
+	case p.HardExpiry && p.MaxPasswordAge == 0:
+		return fmt.Errorf("hard expiry requires a maximum password age")
+	}
+	return nil
+}
+
+// UpdateInput returns the request that makes p the account password policy.
+// Zero reuse prevention and maximum age are left unset, which IAM treats as
+// disabled.
+func (p Policy) UpdateInput() *iam.UpdateAccountPasswordPolicyInput {
+	input := &iam.UpdateAccountPasswordPolicyInput{
+		MinimumPasswordLength:      aws.Int32(int32(p.MinimumLength)),
+		RequireUppercaseCharacters: p.RequireUppercase,
+		RequireLowercaseCharacters: p.RequireLowercase,
+		RequireNumbers:             p.RequireNumbers,
+		RequireSymbols:             p.RequireSymbols,
+		AllowUsersToChangePassword: p.AllowUsersToChangePassword,
+		HardExpiry:                 aws.Bool(p.HardExpiry),
+	}
+	if p.PasswordReusePrevention > 0 {
+		input.PasswordReusePrevention = aws.Int32(int32(p.PasswordReusePrevention))
+	}
+	if p.MaxPasswordAge > 0 {
+		input.MaxPasswordAge = aws.Int32(int32(p.MaxPasswordAge))
+	}
+	return input
+}
+
+// Setting is a single named account password policy setting
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// Settings lists the settings IAM stores for the policy, in a fixed order
+func (p Policy) Settings() []Setting {
+	return []Setting{
+		{"minimum_length", strconv.Itoa(p.MinimumLength)},
+		{"require_uppercase", strconv.FormatBool(p.RequireUppercase)},
+		{"require_lowercase", strconv.FormatBool(p.RequireLowercase)},
+		{"require_numbers", strconv.FormatBool(p.RequireNumbers)},
+		{"require_symbols", strconv.FormatBool(p.RequireSymbols)},
+		{"password_reuse_prevention", strconv.Itoa(p.PasswordReusePrevention)},
+		{"max_password_age", strconv.Itoa(p.MaxPasswordAge)},
+		{"allow_users_to_change_password", strconv.FormatBool(p.AllowUsersToChangePassword)},
+		{"hard_expiry", strconv.FormatBool(p.HardExpiry)},
+	}
+}
+
+// Change is a setting that differs between two policies
+type Change struct {
+	Setting string `json:"setting"`
+	Current string `json:"current"`
+	Desired string `json:"desired"`
+}
+
+// Diff returns the settings that change when going from current to desired.
+// When current is not an account policy every setting is a change.
+func Diff(current, desired Policy) []Change {
+	var changes []Change
+	want := desired.Settings()
+	for i, have := range current.Settings() {
+		if current.Source != SourceAccount {
+			have.Value = "-"
+		}
+		if have.Value != want[i].Value {
+			changes = append(changes, Change{Setting: have.Name, Current: have.Value, Desired: want[i].Value})
+		}
+	}
+	return changes
+}
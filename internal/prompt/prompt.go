@@ -0,0 +1,209 @@
+// Package prompt reads MFA codes, passwords and confirmations for every
+// command. Input comes from flags, environment variables, a file descriptor
+// or piped stdin when available, so commands run in CI and scripts, and from
+// the terminal otherwise. Secrets are never echoed.
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// Environment variables read when the matching flag is not set
+const (
+	EnvMFACode         = "IAMCTL_MFA_CODE"
+	EnvPassword        = "IAMCTL_PASSWORD"
+	EnvCurrentPassword = "IAMCTL_CURRENT_PASSWORD"
+)
+
+// ErrNoTerminal is returned when input is needed but neither a flag,
+// environment variable nor terminal can provide it
+var ErrNoTerminal = errors.New("no terminal available")
+
+// options are bound to the root command's persistent flags
+var options = struct {
+	mfaCode    string
+	passwordFD int
+	yes        bool
+}{passwordFD: -1}
+
+// Terminal and stream hooks, replaced in tests
+var (
+	stdin                = os.Stdin
+	stderr     io.Writer = os.Stderr
+	isTerminal           = func(f *os.File) bool { return term.IsTerminal(int(f.Fd())) }
+	readSecret           = func(f *os.File) ([]byte, error) { return term.ReadPassword(int(f.Fd())) }
+)
+
+// stdinLines is shared so consecutive reads from piped stdin consume one
+// line each
+var (
+	stdinOnce  sync.Once
+	stdinLines *bufio.Reader
+)
+
+// fdLines is shared for the same reason, so --password-fd can carry the
+// current and new password on consecutive lines
+var (
+	fdLines   *bufio.Reader
+	fdLinesFD = -1
+)
+
+// AddFlags registers the input flags as persistent flags of the root command
+func AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&options.mfaCode, "mfa-code", "", "MFA code to use instead of prompting (or set "+EnvMFACode+")")
+	flags.IntVar(&options.passwordFD, "password-fd", -1, "Read the password from this file descriptor (or set "+EnvPassword+")")
+	flags.BoolVarP(&options.yes, "yes", "y", false, "Answer yes to confirmations")
+}
+
+// ExplicitMFACode returns a code given with --mfa-code or IAMCTL_MFA_CODE
+func ExplicitMFACode() (string, bool) {
+	if options.mfaCode != "" {
+		return options.mfaCode, true
+	}
+	if code := os.Getenv(EnvMFACode); code != "" {
+		return code, true
+	}
+	return "", false
+}
+
+// MFACode returns an MFA code from --mfa-code, IAMCTL_MFA_CODE, piped stdin
+// or a hidden terminal prompt
+func MFACode() (string, error) {
+	if code, ok := ExplicitMFACode(); ok {
+		return code, nil
+	}
+	return secret("Enter MFA code: ", "--mfa-code or "+EnvMFACode)
+}
+
+// Code reads one code from piped stdin or a hidden terminal prompt. Unlike
+// MFACode it ignores --mfa-code, for prompts that need several codes.
+func Code(label string) (string, error) {
+	return secret(label+": ", "one code per line on stdin")
+}
+
+// Password returns a password from --password-fd, IAMCTL_PASSWORD, piped
+// stdin or a hidden terminal prompt
+func Password(label string) (string, error) {
+	if password, ok, err := explicitPassword(EnvPassword); ok || err != nil {
+		return password, err
+	}
+	return secret(label+": ", "--password-fd or "+EnvPassword)
+}
+
+// CurrentPassword is Password for the password being replaced. It reads
+// IAMCTL_CURRENT_PASSWORD, or the first line of --password-fd with the new
+// password on the next.
+func CurrentPassword(label string) (string, error) {
+	if password, ok, err := explicitPassword(EnvCurrentPassword); ok || err != nil {
+		return password, err
+	}
+	return secret(label+": ", "--password-fd or "+EnvCurrentPassword)
+}
+
+// NewPassword is Password, but a terminal prompt asks twice and requires the
+// entries to match
+func NewPassword(label string) (string, error) {
+	if password, ok, err := explicitPassword(EnvPassword); ok || err != nil {
+		return password, err
+	}
+	if !isTerminal(stdin) {
+		return secret(label+": ", "--password-fd or "+EnvPassword)
+	}
+
+	password, err := secret(label+": ", "")
+	if err != nil {
+		return "", err
+	}
+	confirm, err := secret("Confirm "+strings.ToLower(label[:1])+label[1:]+": ", "")
+	if err != nil {
+		return "", err
+	}
+	if password != confirm {
+		return "", errors.New("passwords do not match")
+	}
+	return password, nil
+}
+
+// Confirm asks the user to type YES. --yes answers for them; without a
+// terminal the answer is read from piped stdin.
+func Confirm(question string) error {
+	if options.yes {
+		return nil
+	}
+
+	fmt.Fprintf(stderr, "%s Type 'YES' to confirm: ", question)
+	answer, err := readLine()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w to confirm; pass --yes", ErrNoTerminal)
+		}
+		return err
+	}
+	if answer != "YES" {
+		return errors.New("cancelled")
+	}
+	return nil
+}
+
+// explicitPassword reads a password given with --password-fd or the
+// environment variable env
+func explicitPassword(env string) (string, bool, error) {
+	if options.passwordFD >= 0 {
+		if fdLinesFD != options.passwordFD {
+			f := os.NewFile(uintptr(options.passwordFD), "password-fd")
+			if f == nil {
+				return "", true, fmt.Errorf("invalid --password-fd %d", options.passwordFD)
+			}
+			fdLines, fdLinesFD = bufio.NewReader(f), options.passwordFD
+		}
+		line, err := fdLines.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+			return "", true, fmt.Errorf("failed to read password from fd %d: %w", options.passwordFD, err)
+		}
+		return strings.TrimRight(line, "\r\n"), true, nil
+	}
+	if password := os.Getenv(env); password != "" {
+		return password, true, nil
+	}
+	return "", false, nil
+}
+
+// secret reads a line from piped stdin, or from the terminal with echo off.
+// hint names the non-interactive alternatives for the error message.
+func secret(label, hint string) (string, error) {
+	if !isTerminal(stdin) {
+		value, err := readLine()
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("%w; use %s", ErrNoTerminal, hint)
+		}
+		return value, err
+	}
+
+	fmt.Fprint(stderr, label)
+	value, err := readSecret(stdin)
+	fmt.Fprintln(stderr) // Add a newline after hidden input
+	if err != nil {
+		return "", fmt.Errorf("failed to read from terminal: %w", err)
+	}
+	return string(value), nil
+}
+
+// readLine reads one line from stdin without its line ending
+func readLine() (string, error) {
+	stdinOnce.Do(func() { stdinLines = bufio.NewReader(stdin) })
+
+	line, err := stdinLines.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,161 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"testing"
+)
+
+// withStdin feeds input through a pipe as non-terminal stdin and resets the
+// package state afterwards
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString(input); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	oldStdin, oldStderr, oldOptions := stdin, stderr, options
+	stdin, stderr = r, io.Discard
+	stdinOnce, stdinLines = sync.Once{}, nil
+	fdLines, fdLinesFD = nil, -1
+	t.Cleanup(func() {
+		r.Close()
+		stdin, stderr, options = oldStdin, oldStderr, oldOptions
+		stdinOnce, stdinLines = sync.Once{}, (*bufio.Reader)(nil)
+		fdLines, fdLinesFD = nil, -1
+	})
+}
+
+func TestMFACodeSources(t *testing.T) {
+	withStdin(t, "111111\n")
+	t.Setenv(EnvMFACode, "")
+
+	// Piped stdin
+	code, err := MFACode()
+	if err != nil || code != "111111" {
+		t.Errorf("MFACode() from stdin = %q, %v", code, err)
+	}
+
+	// Environment beats stdin
+	t.Setenv(EnvMFACode, "222222")
+	if code, _ := MFACode(); code != "222222" {
+		t.Errorf("MFACode() from env = %q", code)
+	}
+
+	// The flag beats the environment
+	options.mfaCode = "333333"
+	if code, _ := MFACode(); code != "333333" {
+		t.Errorf("MFACode() from flag = %q", code)
+	}
+}
+
+func TestNoTerminal(t *testing.T) {
+	withStdin(t, "")
+	t.Setenv(EnvMFACode, "")
+	t.Setenv(EnvPassword, "")
+
+	if _, err := MFACode(); !errors.Is(err, ErrNoTerminal) {
+		t.Errorf("MFACode() error = %v, want ErrNoTerminal", err)
+	}
+	if _, err := NewPassword("Enter new password"); !errors.Is(err, ErrNoTerminal) {
+		t.Errorf("NewPassword() error = %v, want ErrNoTerminal", err)
+	}
+	if err := Confirm("Proceed?"); !errors.Is(err, ErrNoTerminal) {
+		t.Errorf("Confirm() error = %v, want ErrNoTerminal", err)
+	}
+
+	options.yes = true
+	if err := Confirm("Proceed?"); err != nil {
+		t.Errorf("Confirm() with --yes = %v", err)
+	}
+}
+
+func TestPasswordFD(t *testing.T) {
+	withStdin(t, "")
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	w.WriteString("correct horse battery staple\n")
+	w.Close()
+
+	options.passwordFD = int(r.Fd())
+	password, err := NewPassword("Enter new password")
+	if err != nil || password != "correct horse battery staple" {
+		t.Errorf("NewPassword() = %q, %v", password, err)
+	}
+}
+
+func TestPasswordFDCarriesCurrentAndNew(t *testing.T) {
+	withStdin(t, "")
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	w.WriteString("old password\nnew password\n")
+	w.Close()
+
+	options.passwordFD = int(r.Fd())
+
+	current, err := CurrentPassword("Enter current password")
+	if err != nil || current != "old password" {
+		t.Errorf("CurrentPassword() = %q, %v", current, err)
+	}
+	password, err := NewPassword("Enter new password")
+	if err != nil || password != "new password" {
+		t.Errorf("NewPassword() = %q, %v", password, err)
+	}
+}
+
+func TestCurrentPasswordFromEnv(t *testing.T) {
+	withStdin(t, "")
+	t.Setenv(EnvCurrentPassword, "old password")
+	t.Setenv(EnvPassword, "new password")
+
+	current, err := CurrentPassword("Enter current password")
+	if err != nil || current != "old password" {
+		t.Errorf("CurrentPassword() = %q, %v", current, err)
+	}
+	password, err := NewPassword("Enter new password")
+	if err != nil || password != "new password" {
+		t.Errorf("NewPassword() = %q, %v", password, err)
+	}
+}
+
+func TestConfirmFromStdin(t *testing.T) {
+	withStdin(t, "YES\nno\n")
+
+	if err := Confirm("Proceed?"); err != nil {
+		t.Errorf("Confirm() = %v, want nil for YES", err)
+	}
+	if err := Confirm("Proceed?"); err == nil {
+		t.Error("Confirm() accepted an answer other than YES")
+	}
+}
+
+func TestTerminalSecretIsHidden(t *testing.T) {
+	withStdin(t, "")
+	t.Setenv(EnvMFACode, "")
+
+	oldIsTerminal, oldReadSecret := isTerminal, readSecret
+	isTerminal = func(*os.File) bool { return true }
+	readSecret = func(*os.File) ([]byte, error) { return []byte("444444"), nil }
+	defer func() { isTerminal, readSecret = oldIsTerminal, oldReadSecret }()
+
+	if code, err := MFACode(); err != nil || code != "444444" {
+		t.Errorf("MFACode() from terminal = %q, %v", code, err)
+	}
+}
@@ -0,0 +1,147 @@
+// Package seedbox seals MFA seeds to a recipient's X25519 public key so an
+// administrator can hand a provisioned device to its owner without the seed
+// ever being readable in transit or at rest.
+package seedbox
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/yourusername/iamctl/internal/config"
+)
+
+var keyNamePattern = regexp.MustCompile(`^[A-Za-z0-9._@-]+$`)
+
+// Key is a recipient's named X25519 private key
+type Key struct {
+	Name       string
+	PrivateKey *ecdh.PrivateKey
+}
+
+// PublicKey is a recipient's named X25519 public key
+type PublicKey struct {
+	Name string `json:"name"`
+	Key  string `json:"public_key"`
+}
+
+type privateKeyFile struct {
+	Name string `json:"name"`
+	Key  string `json:"private_key"`
+}
+
+// GenerateKey creates a seed key for name under the iamctl state directory
+// and writes its public half to pubPath for the administrator
+func GenerateKey(name, pubPath string) error {
+	if !keyNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid key name %q", name)
+	}
+
+	keyPath, err := config.Path("seed", "keys", name+".key")
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(keyPath); err == nil {
+		return fmt.Errorf("seed key %q already exists", name)
+	}
+
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate seed key: %w", err)
+	}
+
+	data, err := json.MarshalIndent(privateKeyFile{
+		Name: name,
+		Key:  base64.StdEncoding.EncodeToString(priv.Bytes()),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write seed key: %w", err)
+	}
+
+	public, err := json.MarshalIndent(PublicKey{
+		Name: name,
+		Key:  base64.StdEncoding.EncodeToString(priv.PublicKey().Bytes()),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(pubPath, public, 0644); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+	return nil
+}
+
+// LoadKey loads the seed key stored for name
+func LoadKey(name string) (*Key, error) {
+	if !keyNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid key name %q", name)
+	}
+
+	keyPath, err := config.Path("seed", "keys", name+".key")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("seed key %q not found, create it with 'iamctl mfa seed keygen %s'", name, name)
+		}
+		return nil, fmt.Errorf("failed to read seed key: %w", err)
+	}
+
+	var stored privateKeyFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse seed key: %w", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(stored.Key)
+	if err != nil {
+		return nil, fmt.Errorf("seed key %q is corrupt", name)
+	}
+	priv, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("seed key %q is corrupt", name)
+	}
+
+	return &Key{Name: stored.Name, PrivateKey: priv}, nil
+}
+
+// ReadPublicKey reads a public key file shared by a recipient
+func ReadPublicKey(path string) (PublicKey, error) {
+	var public PublicKey
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return public, fmt.Errorf("failed to read public key: %w", err)
+	}
+	if err := json.Unmarshal(data, &public); err != nil {
+		return public, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	if !keyNamePattern.MatchString(public.Name) {
+		return public, fmt.Errorf("invalid key name %q", public.Name)
+	}
+	if _, err := public.ecdh(); err != nil {
+		return public, err
+	}
+
+	return public, nil
+}
+
+func (p PublicKey) ecdh() (*ecdh.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(p.Key)
+	if err != nil {
+		return nil, fmt.Errorf("public key %q is corrupt", p.Name)
+	}
+	key, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("public key %q is corrupt", p.Name)
+	}
+	return key, nil
+}
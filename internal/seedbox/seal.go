@@ -0,0 +1,167 @@
+package seedbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Content types of a sealed payload
+const (
+	ContentSeed = "base32-seed"
+	ContentQR   = "qr-png"
+)
+
+// ErrNotSealed is returned for data that is not a sealed file
+var ErrNotSealed = errors.New("not a sealed seed file")
+
+const (
+	envelopeVersion = 1
+	hkdfInfo        = "iamctl seedbox v1"
+)
+
+// envelope is the on-disk form of a sealed payload. The payload is encrypted
+// with AES-256-GCM under a key derived from an ephemeral X25519 exchange with
+// the recipient's public key.
+type envelope struct {
+	Version      int    `json:"version"`
+	Recipient    string `json:"recipient"`
+	ContentType  string `json:"content_type"`
+	EphemeralKey []byte `json:"ephemeral_key"`
+	Nonce        []byte `json:"nonce"`
+	Ciphertext   []byte `json:"ciphertext"`
+}
+
+// Seal encrypts payload so only the holder of recipient's private key can
+// read it
+func Seal(recipient PublicKey, contentType string, payload []byte) ([]byte, error) {
+	recipientKey, err := recipient.ecdh()
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+
+	gcm, err := newGCM(ephemeral, recipientKey, ephemeral.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	env := envelope{
+		Version:      envelopeVersion,
+		Recipient:    recipient.Name,
+		ContentType:  contentType,
+		EphemeralKey: ephemeral.PublicKey().Bytes(),
+		Nonce:        make([]byte, gcm.NonceSize()),
+	}
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	env.Ciphertext = gcm.Seal(nil, env.Nonce, payload, env.additionalData())
+
+	return json.MarshalIndent(env, "", "  ")
+}
+
+// Open decrypts a sealed payload with key and returns its content type
+func Open(key *Key, data []byte) (string, []byte, error) {
+	env, err := parseEnvelope(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if env.Recipient != key.Name {
+		return "", nil, fmt.Errorf("sealed for %q, not %q", env.Recipient, key.Name)
+	}
+
+	ephemeral, err := ecdh.X25519().NewPublicKey(env.EphemeralKey)
+	if err != nil {
+		return "", nil, errors.New("sealed file is corrupt")
+	}
+
+	gcm, err := newGCM(key.PrivateKey, ephemeral, ephemeral)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(env.Nonce) != gcm.NonceSize() {
+		return "", nil, errors.New("sealed file is corrupt")
+	}
+
+	payload, err := gcm.Open(nil, env.Nonce, env.Ciphertext, env.additionalData())
+	if err != nil {
+		return "", nil, errors.New("sealed file could not be decrypted with this key")
+	}
+	return env.ContentType, payload, nil
+}
+
+// Recipient returns the name of the key a sealed file was sealed to, or an
+// error if data is not a sealed file
+func Recipient(data []byte) (string, error) {
+	env, err := parseEnvelope(data)
+	if err != nil {
+		return "", err
+	}
+	return env.Recipient, nil
+}
+
+func parseEnvelope(data []byte) (*envelope, error) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Version == 0 {
+		return nil, ErrNotSealed
+	}
+	if env.Version != envelopeVersion {
+		return nil, fmt.Errorf("unsupported sealed file version %d", env.Version)
+	}
+	return &env, nil
+}
+
+// additionalData binds the recipient and content type to the ciphertext
+func (e *envelope) additionalData() []byte {
+	return []byte(e.Recipient + "\x00" + e.ContentType)
+}
+
+// newGCM derives the payload cipher from an X25519 exchange. The ephemeral
+// public key is mixed into the derivation so each file uses a fresh key.
+func newGCM(priv *ecdh.PrivateKey, peer, ephemeral *ecdh.PublicKey) (cipher.AEAD, error) {
+	shared, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, fmt.Errorf("key exchange failed: %w", err)
+	}
+
+	key, err := hkdf.Key(sha256.New, shared, ephemeral.Bytes(), hkdfInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// OpenFile decrypts a sealed file with the local key it was sealed to
+func OpenFile(path string) (string, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read sealed file: %w", err)
+	}
+
+	recipient, err := Recipient(data)
+	if err != nil {
+		return "", nil, err
+	}
+	key, err := LoadKey(recipient)
+	if err != nil {
+		return "", nil, err
+	}
+	return Open(key, data)
+}
@@ -0,0 +1,68 @@
+package seedbox
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	t.Setenv("IAMCTL_HOME", t.TempDir())
+	pubDir := t.TempDir()
+
+	for _, name := range []string{"bob", "mallory"} {
+		if err := GenerateKey(name, filepath.Join(pubDir, name+".pub")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	public, err := ReadPublicKey(filepath.Join(pubDir, "bob.pub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seed := []byte("JBSWY3DPEHPK3PXP")
+	sealed, err := Seal(public, ContentSeed, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, seed) {
+		t.Fatal("sealed file contains the plaintext seed")
+	}
+
+	if recipient, err := Recipient(sealed); err != nil || recipient != "bob" {
+		t.Errorf("Recipient() = %q, %v, want bob", recipient, err)
+	}
+
+	bob, err := LoadKey("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	contentType, payload, err := Open(bob, sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != ContentSeed || !bytes.Equal(payload, seed) {
+		t.Errorf("Open() = %q, %q, want %q, %q", contentType, payload, ContentSeed, seed)
+	}
+
+	// Another recipient's key must not open the file, even under bob's name
+	mallory, err := LoadKey("mallory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mallory.Name = "bob"
+	if _, _, err := Open(mallory, sealed); err == nil {
+		t.Error("expected another key to fail to open the file")
+	}
+
+	// Tampering with the content type is detected
+	tampered := bytes.Replace(sealed, []byte(ContentSeed), []byte(ContentQR), 1)
+	if _, _, err := Open(bob, tampered); err == nil {
+		t.Error("expected a tampered file to fail to open")
+	}
+
+	if _, err := Recipient([]byte("JBSWY3DPEHPK3PXP\n")); err == nil {
+		t.Error("expected a plain seed file to be rejected")
+	}
+}
@@ -0,0 +1,250 @@
+// Package session caches MFA-backed temporary credentials on disk so later
+// iamctl commands can reuse them until they expire. Cache entries are
+// encrypted with AES-256-GCM under a per-user key held in the OS keyring, so
+// copying the state directory alone does not reveal them.
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/iamctl/internal/config"
+	"github.com/yourusername/iamctl/internal/keyring"
+)
+
+// Session kinds
+const (
+	KindSessionToken = "session-token"
+	KindAssumeRole   = "assume-role"
+)
+
+// keyringService and keyringAccount name the cache key in the OS keyring
+const (
+	keyringService = "iamctl"
+	keyringAccount = "session-cache-key"
+)
+
+// keys holds the cache encryption key; tests replace it
+var keys keyring.Store = keyring.System()
+
+// refreshMargin treats sessions this close to expiry as already expired, so
+// commands never start with credentials that lapse mid-way
+const refreshMargin = time.Minute
+
+// Entry is a cached set of MFA-backed temporary credentials
+type Entry struct {
+	Profile         string    `json:"profile"`
+	Kind            string    `json:"kind"`
+	RoleARN         string    `json:"role_arn,omitempty"`
+	SerialNumber    string    `json:"serial_number"`
+	AccessKeyID     string    `json:"access_key_id"`
+	SecretAccessKey string    `json:"secret_access_key"`
+	SessionToken    string    `json:"session_token"`
+	Expiration      time.Time `json:"expiration"`
+}
+
+// Valid reports whether the entry can still be used for at least margin
+func (e *Entry) Valid(margin time.Duration) bool {
+	return time.Until(e.Expiration) > margin
+}
+
+// Save encrypts and stores an entry, replacing any session for its profile
+func Save(entry *Entry) error {
+	path, err := entryPath(entry.Profile)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := seal(plaintext)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, ciphertext, 0600); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// Load returns the cached session for profile, or nil when there is none or
+// it has expired. Expired sessions, and sessions that no longer decrypt
+// because the key in the keyring was replaced, are removed.
+func Load(profile string) (*Entry, error) {
+	path, err := entryPath(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	plaintext, err := open(ciphertext)
+	if errors.Is(err, errUndecryptable) {
+		os.Remove(path)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(plaintext, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	if !entry.Valid(refreshMargin) {
+		os.Remove(path)
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// Delete removes the cached session for profile
+func Delete(profile string) error {
+	path, err := entryPath(profile)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove session: %w", err)
+	}
+	return nil
+}
+
+// List returns every cached, unexpired session
+func List() ([]*Entry, error) {
+	dir, err := config.Path("sessions", "")
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.session"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	for _, path := range paths {
+		profile, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(path), ".session"))
+		if err != nil {
+			continue
+		}
+		entry, err := Load(profile)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// ProfileName normalizes an empty profile to "default", matching how
+// iamctl loads configuration
+func ProfileName(profile string) string {
+	if profile == "" {
+		return "default"
+	}
+	return profile
+}
+
+func entryPath(profile string) (string, error) {
+	return config.Path("sessions", url.PathEscape(ProfileName(profile))+".session")
+}
+
+// errUndecryptable means a cache entry was sealed under a different key
+var errUndecryptable = errors.New("session cache entry could not be decrypted")
+
+// cacheKey returns the cache encryption key from the OS keyring, creating
+// it on first use
+func cacheKey() ([]byte, error) {
+	encoded, err := keys.Get(keyringService, keyringAccount)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, errors.New("session cache key in the OS keyring is corrupt")
+		}
+		return key, nil
+	}
+	if !errors.Is(err, keyring.ErrNotFound) {
+		return nil, fmt.Errorf("failed to read session cache key: %w", err)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate session cache key: %w", err)
+	}
+	if err := keys.Set(keyringService, keyringAccount, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, fmt.Errorf("failed to store session cache key: %w", err)
+	}
+
+	// Earlier versions kept the key next to the cache
+	if path, err := config.Path("session.key"); err == nil {
+		os.Remove(path)
+	}
+	return key, nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	key, err := cacheKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errUndecryptable
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, errUndecryptable
+	}
+	return plaintext, nil
+}
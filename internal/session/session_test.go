@@ -0,0 +1,157 @@
+package session
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/iamctl/internal/keyring"
+)
+
+// memoryKeyring stands in for the OS keyring
+type memoryKeyring map[string]string
+
+func (m memoryKeyring) Get(service, account string) (string, error) {
+	secret, ok := m[service+"/"+account]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return secret, nil
+}
+
+func (m memoryKeyring) Set(service, account, secret string) error {
+	m[service+"/"+account] = secret
+	return nil
+}
+
+func (m memoryKeyring) Delete(service, account string) error {
+	delete(m, service+"/"+account)
+	return nil
+}
+
+// setupCache points the cache at a fresh state directory and keyring
+func setupCache(t *testing.T) (string, memoryKeyring) {
+	dir := t.TempDir()
+	t.Setenv("IAMCTL_HOME", dir)
+
+	store := memoryKeyring{}
+	saved := keys
+	keys = store
+	t.Cleanup(func() { keys = saved })
+	return dir, store
+}
+
+func testEntry(profile string, expiration time.Time) *Entry {
+	return &Entry{
+		Profile:         profile,
+		Kind:            KindSessionToken,
+		SerialNumber:    "arn:aws:iam::123456789012:mfa/user",
+		AccessKeyID:     "ASIAEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY",
+		SessionToken:    "session-token",
+		Expiration:      expiration,
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir, store := setupCache(t)
+
+	// A key left next to the cache by an earlier version is removed
+	legacyKey := filepath.Join(dir, "session.key")
+	if err := os.WriteFile(legacyKey, make([]byte, 32), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := testEntry("dev", time.Now().Add(time.Hour).Round(0))
+	if err := Save(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded == nil || loaded.SecretAccessKey != entry.SecretAccessKey || !loaded.Expiration.Equal(entry.Expiration) {
+		t.Fatalf("Load() = %+v, want %+v", loaded, entry)
+	}
+
+	// The secret must not be stored in plaintext, and the key must live in
+	// the keyring rather than next to the cache
+	raw, err := os.ReadFile(filepath.Join(dir, "sessions", "dev.session"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte(entry.SecretAccessKey)) {
+		t.Error("session file contains the plaintext secret")
+	}
+	if _, err := store.Get(keyringService, keyringAccount); err != nil {
+		t.Errorf("cache key not in the keyring: %v", err)
+	}
+	if _, err := os.Stat(legacyKey); !os.IsNotExist(err) {
+		t.Error("session.key is still next to the cache")
+	}
+
+	entries, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Profile != "dev" {
+		t.Errorf("List() = %+v, want the dev session", entries)
+	}
+
+	if err := Delete("dev"); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, _ := Load("dev"); loaded != nil {
+		t.Error("session still loads after Delete")
+	}
+}
+
+func TestLoadExpired(t *testing.T) {
+	setupCache(t)
+
+	// Sessions inside the refresh margin count as expired
+	if err := Save(testEntry("default", time.Now().Add(30*time.Second))); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded != nil {
+		t.Errorf("Load() = %+v, want nil for an expired session", loaded)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	setupCache(t)
+
+	loaded, err := Load("missing")
+	if err != nil || loaded != nil {
+		t.Errorf("Load() = %+v, %v, want nil, nil", loaded, err)
+	}
+}
+
+func TestLoadUnreadable(t *testing.T) {
+	dir, store := setupCache(t)
+
+	if err := Save(testEntry("dev", time.Now().Add(time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+
+	// A session sealed under a key that is no longer in the keyring is
+	// discarded, not reported
+	if err := store.Delete(keyringService, keyringAccount); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load("dev")
+	if err != nil || loaded != nil {
+		t.Fatalf("Load() = %+v, %v, want nil, nil", loaded, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sessions", "dev.session")); !os.IsNotExist(err) {
+		t.Error("undecryptable session file was not removed")
+	}
+}
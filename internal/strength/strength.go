@@ -0,0 +1,332 @@
+// Package strength estimates how hard a password is to guess. Character
+// class rules pass passwords like "Password12345!", so the estimate also
+// discounts common words, keyboard patterns, sequences, repeats, years and
+// the user's own names.
+package strength
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MaxScore is the best possible score
+const MaxScore = 4
+
+// scoreBits are the minimum estimated entropy, in bits, for scores 1 to 4
+var scoreBits = [MaxScore]float64{25, 40, 55, 70}
+
+// minInputLen is the shortest user input looked for in a password
+const minInputLen = 3
+
+// Result is the strength estimate of a password
+type Result struct {
+	Score    int      `json:"score"`
+	Entropy  float64  `json:"entropy_bits"`
+	Feedback []string `json:"feedback,omitempty"`
+}
+
+// match is a guessable span of the password, replacing the per-character
+// entropy of runes [start, end) with entropy bits
+type match struct {
+	start, end int
+	entropy    float64
+	feedback   string
+}
+
+// Estimate scores password from 0 (trivially guessable) to 4 (strong).
+// userInputs are names the password should not contain, such as the user
+// name and account alias.
+func Estimate(password string, userInputs ...string) Result {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return Result{Feedback: []string{"password is empty"}}
+	}
+
+	// 1. Naive entropy of each character from the classes in use
+	perRune := math.Log2(float64(charsetSize(runes)))
+
+	// 2. Find guessable spans and keep the non-overlapping ones that save
+	// the most entropy
+	var candidates []match
+	candidates = append(candidates, userInputMatches(runes, userInputs)...)
+	candidates = append(candidates, dictionaryMatches(runes)...)
+	candidates = append(candidates, keyboardMatches(runes)...)
+	candidates = append(candidates, sequenceMatches(runes)...)
+	candidates = append(candidates, repeatMatches(runes, perRune)...)
+	candidates = append(candidates, yearMatches(runes)...)
+
+	savings := func(m match) float64 { return float64(m.end-m.start)*perRune - m.entropy }
+	sort.SliceStable(candidates, func(i, j int) bool { return savings(candidates[i]) > savings(candidates[j]) })
+
+	used := make([]bool, len(runes))
+	entropy := 0.0
+	var feedback []string
+	seen := map[string]bool{}
+	for _, m := range candidates {
+		if savings(m) <= 0 || overlaps(used, m) {
+			continue
+		}
+		for i := m.start; i < m.end; i++ {
+			used[i] = true
+		}
+		entropy += m.entropy
+		if !seen[m.feedback] {
+			seen[m.feedback] = true
+			feedback = append(feedback, m.feedback)
+		}
+	}
+	for _, u := range used {
+		if !u {
+			entropy += perRune
+		}
+	}
+
+	// 3. Score and advise
+	result := Result{Entropy: math.Round(entropy*10) / 10, Feedback: feedback}
+	for result.Score < MaxScore && entropy >= scoreBits[result.Score] {
+		result.Score++
+	}
+	if len(runes) < 12 {
+		result.Feedback = append(result.Feedback, "use at least 12 characters")
+	}
+	if result.Score < 3 && len(feedback) == 0 {
+		result.Feedback = append(result.Feedback, "add more unpredictable words or characters")
+	}
+	return result
+}
+
+// charsetSize estimates the alphabet an attacker must search from the
+// character classes present. Letters and digits outside ASCII count as
+// their class, not as symbols.
+func charsetSize(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+			other = other || r > unicode.MaxASCII
+		case unicode.IsUpper(r):
+			upper = true
+			other = other || r > unicode.MaxASCII
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsLetter(r), r > unicode.MaxASCII:
+			other = true
+		default:
+			symbol = true
+		}
+	}
+
+	size := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.present {
+			size += class.size
+		}
+	}
+	return size
+}
+
+// overlaps reports whether any rune of m is already part of a match
+func overlaps(used []bool, m match) bool {
+	for i := m.start; i < m.end; i++ {
+		if used[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// caseBits is the extra entropy of the capitalisation of word: none for all
+// lower case, one bit for a capitalised first letter or all upper case, and
+// one bit per upper-case letter otherwise
+func caseBits(word []rune) float64 {
+	upper := 0
+	for _, r := range word {
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+	switch {
+	case upper == 0:
+		return 0
+	case upper == len(word), upper == 1 && unicode.IsUpper(word[0]):
+		return 1
+	default:
+		return float64(upper)
+	}
+}
+
+// userInputMatches finds the user's own names, which an attacker tries first
+func userInputMatches(runes []rune, inputs []string) []match {
+	lower := strings.ToLower(string(runes))
+	var matches []match
+	for _, input := range inputs {
+		input = strings.ToLower(input)
+		if len([]rune(input)) < minInputLen {
+			continue
+		}
+		for offset := 0; ; {
+			i := strings.Index(lower[offset:], input)
+			if i < 0 {
+				break
+			}
+			start := len([]rune(lower[:offset+i]))
+			end := start + len([]rune(input))
+			matches = append(matches, match{start, end, 1 + caseBits(runes[start:end]), "contains your user name or account alias"})
+			offset += i + len(input)
+		}
+	}
+	return matches
+}
+
+// dictionaryMatches finds common words and passwords, also when spelt with
+// substitutions such as p4ssw0rd
+func dictionaryMatches(runes []rune) []match {
+	lower := []rune(strings.ToLower(string(runes)))
+	unleet := make([]rune, len(lower))
+	for i, r := range lower {
+		if l, ok := leet[r]; ok {
+			unleet[i] = l
+		} else {
+			unleet[i] = r
+		}
+	}
+
+	var matches []match
+	for start := range lower {
+		for end := start + 3; end <= len(lower) && end-start <= maxWordLen; end++ {
+			word, substituted := string(lower[start:end]), false
+			rank, ok := wordRanks[word]
+			if !ok {
+				word, substituted = string(unleet[start:end]), true
+				rank, ok = wordRanks[word]
+			}
+			if !ok {
+				continue
+			}
+
+			entropy := math.Log2(float64(rank)) + 1 + caseBits(runes[start:end])
+			if substituted {
+				entropy++
+			}
+			matches = append(matches, match{start, end, entropy, fmt.Sprintf("contains the common word %q", word)})
+		}
+	}
+	return matches
+}
+
+// keyboardMatches finds runs of four or more adjacent keys on one keyboard
+// row, in either direction
+func keyboardMatches(runes []rune) []match {
+	lower := []rune(strings.ToLower(string(runes)))
+	var matches []match
+	for start := 0; start < len(lower); {
+		end := start + 1
+		for end < len(lower) && adjacentKeys(lower[end-1], lower[end]) {
+			end++
+		}
+		if end-start >= 4 {
+			// Any starting key, the run length and its direction
+			entropy := math.Log2(47) + math.Log2(float64(end-start)) + 1 + caseBits(runes[start:end])
+			matches = append(matches, match{start, end, entropy, fmt.Sprintf("contains the keyboard pattern %q", string(runes[start:end]))})
+		}
+		start = end
+	}
+	return matches
+}
+
+// adjacentKeys reports whether b is next to a on a keyboard row
+func adjacentKeys(a, b rune) bool {
+	for _, row := range keyboardRows {
+		i := strings.IndexRune(row, a)
+		if i < 0 {
+			continue
+		}
+		j := strings.IndexRune(row, b)
+		if j >= 0 && (j == i+1 || j == i-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// sequenceMatches finds runs of three or more letters or digits in
+// alphabetical or numerical order, such as abc, 12345 or 987
+func sequenceMatches(runes []rune) []match {
+	lower := []rune(strings.ToLower(string(runes)))
+	var matches []match
+	for start := 0; start+1 < len(lower); {
+		delta := lower[start+1] - lower[start]
+		end := start + 1
+		if delta == 1 || delta == -1 {
+			for end < len(lower) && lower[end]-lower[end-1] == delta && sameSequenceClass(lower[start], lower[end]) {
+				end++
+			}
+		}
+		if end-start >= 3 {
+			alphabet := 26.0
+			if unicode.IsDigit(lower[start]) {
+				alphabet = 10
+			}
+			entropy := math.Log2(alphabet) + math.Log2(float64(end-start))
+			if delta < 0 {
+				entropy++
+			}
+			matches = append(matches, match{start, end, entropy, fmt.Sprintf("contains the sequence %q", string(runes[start:end]))})
+			start = end
+			continue
+		}
+		start++
+	}
+	return matches
+}
+
+// sameSequenceClass reports whether a and b are both ASCII letters or both
+// digits
+func sameSequenceClass(a, b rune) bool {
+	isLetter := func(r rune) bool { return r >= 'a' && r <= 'z' }
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+	return (isLetter(a) && isLetter(b)) || (isDigit(a) && isDigit(b))
+}
+
+// repeatMatches finds a block of one or more characters repeated back to
+// back, such as aaaa or abcabc. The repeats add only the bits needed to
+// count them.
+func repeatMatches(runes []rune, perRune float64) []match {
+	var matches []match
+	for start := range runes {
+		for size := 1; start+2*size <= len(runes); size++ {
+			count := 1
+			for start+(count+1)*size <= len(runes) && string(runes[start+count*size:start+(count+1)*size]) == string(runes[start:start+size]) {
+				count++
+			}
+			// Single characters must repeat three times to count
+			if count < 2 || (size == 1 && count < 3) {
+				continue
+			}
+			end := start + count*size
+			entropy := float64(size)*perRune + math.Log2(float64(count))
+			matches = append(matches, match{start, end, entropy, fmt.Sprintf("contains the repeated %q", string(runes[start:end]))})
+		}
+	}
+	return matches
+}
+
+// yearMatches finds years from 1900 to 2099
+func yearMatches(runes []rune) []match {
+	var matches []match
+	for start := 0; start+4 <= len(runes); start++ {
+		year := string(runes[start : start+4])
+		if (strings.HasPrefix(year, "19") || strings.HasPrefix(year, "20")) &&
+			unicode.IsDigit(runes[start+2]) && unicode.IsDigit(runes[start+3]) {
+			matches = append(matches, match{start, start + 4, math.Log2(200), "contains a year"})
+		}
+	}
+	return matches
+}
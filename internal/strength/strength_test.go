@@ -0,0 +1,89 @@
+package strength
+
+import (
+	"strings"
+	"testing"
+)
+
+func hasFeedback(result Result, text string) bool {
+	for _, feedback := range result.Feedback {
+		if strings.Contains(feedback, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEstimateWeakPasswords(t *testing.T) {
+	tests := []struct {
+		password string
+		feedback string
+	}{
+		{"Password12345!", `common word "password"`},
+		{"P@ssw0rd2024!!", `common word "password"`},
+		{"poiuytrewq;lkjh", "keyboard pattern"},
+		{"abcdefghijklmnop", "sequence"},
+		{"aaaaaaaaaaaaaaaa", "repeated"},
+		{"xK9xK9xK9xK9xK9x", "repeated"},
+		{"Summer1987winter", "year"},
+	}
+
+	for _, tt := range tests {
+		result := Estimate(tt.password)
+		if result.Score > 2 {
+			t.Errorf("Estimate(%q) score = %d (%.1f bits), want at most 2", tt.password, result.Score, result.Entropy)
+		}
+		if !hasFeedback(result, tt.feedback) {
+			t.Errorf("Estimate(%q) feedback = %v, want %q", tt.password, result.Feedback, tt.feedback)
+		}
+	}
+}
+
+func TestEstimateStrongPasswords(t *testing.T) {
+	for _, password := range []string{
+		"h7#Kq2!vRz$9pLw&",
+		"tangerine-gravel-okapi-lantern",
+		"Zq8@nf3#Lp0!Wt5%",
+	} {
+		result := Estimate(password)
+		if result.Score < 3 {
+			t.Errorf("Estimate(%q) score = %d (%.1f bits, %v), want at least 3", password, result.Score, result.Entropy, result.Feedback)
+		}
+	}
+}
+
+func TestEstimateUserInputs(t *testing.T) {
+	password := "Alice-Prod-Xq7#Lz"
+	without := Estimate(password)
+	with := Estimate(password, "alice", "acme-prod")
+
+	if with.Entropy >= without.Entropy {
+		t.Errorf("Expected the user name to lower the estimate: %.1f >= %.1f", with.Entropy, without.Entropy)
+	}
+	if !hasFeedback(with, "user name") {
+		t.Errorf("feedback = %v, want the user name reported", with.Feedback)
+	}
+
+	// Inputs too short to be meaningful are ignored
+	if got := Estimate(password, "al"); got.Entropy != without.Entropy {
+		t.Errorf("Expected a two letter input to be ignored, got %.1f", got.Entropy)
+	}
+}
+
+func TestEstimateUnicode(t *testing.T) {
+	// Accented letters are letters, not symbols, and widen the alphabet
+	ascii := Estimate("zebrasolvetram")
+	accented := Estimate("zébrasolvétram")
+	if accented.Entropy <= ascii.Entropy {
+		t.Errorf("Expected non-ASCII letters to widen the alphabet: %.1f <= %.1f", accented.Entropy, ascii.Entropy)
+	}
+	if charsetSize([]rune("é")) != 26+100 {
+		t.Errorf("charsetSize(é) = %d", charsetSize([]rune("é")))
+	}
+}
+
+func TestEstimateEmpty(t *testing.T) {
+	if result := Estimate(""); result.Score != 0 || len(result.Feedback) == 0 {
+		t.Errorf("Estimate(\"\") = %+v", result)
+	}
+}
@@ -0,0 +1,74 @@
+package strength
+
+import "strings"
+
+// commonWords are frequently used passwords and password building blocks,
+// most common first. A word's rank is its position in this list.
+var commonWords = strings.Fields(`
+password 123456 qwerty letmein welcome admin iloveyou monkey dragon
+football baseball master sunshine princess shadow superman trustno1 login
+abc123 passw0rd starwars whatever freedom hello charlie secret changeme
+default guest access test user root batman michael jordan pepper thomas
+ashley jessica hunter killer soccer hockey ranger buster tigger summer
+winter spring autumn fall january february march april may june july
+august september october november december monday tuesday wednesday
+thursday friday saturday sunday love lovely angel flower family friend
+mother father baby purple orange yellow silver golden black white green
+blue red computer internet server cloud amazon aws account company office
+work business manager security private system network database backup
+welcome1 pass word qwertyuiop asdfgh zxcvbn iloveu cookie chocolate cheese
+pizza coffee banana apple cherry jasmine diamond crystal matrix ninja
+pokemon mustang ferrari porsche corvette harley yankees lakers eagles
+cowboys steelers packers arsenal chelsea liverpool barcelona madrid
+london paris berlin tokyo america canada london england germany france
+india china russia spain italy brazil mexico australia george william
+robert richard daniel matthew joseph andrew joshua david james john
+jennifer michelle amanda melissa nicole elizabeth sarah hannah emily
+maggie bailey buddy lucky max molly sophie rocky bandit coco teddy
+dolphin tiger lion bear wolf eagle falcon phoenix dragonfly butterfly
+heaven hell god jesus christ angel devil demon magic wizard merlin
+letmein1 admin123 root123 test123 qwerty123 password1 welcome123
+happy smile sunny rainbow star moon sun earth ocean river mountain
+forest garden house home school college student teacher doctor
+money dollar euro bitcoin crypto gold silverado rock music guitar
+piano drum dance party beach island paradise summer2024 winter2024
+`)
+
+// wordRanks maps each common word to its 1-based rank
+var wordRanks = func() map[string]int {
+	ranks := make(map[string]int, len(commonWords))
+	for i, word := range commonWords {
+		if _, ok := ranks[word]; !ok {
+			ranks[word] = i + 1
+		}
+	}
+	return ranks
+}()
+
+// maxWordLen bounds the substrings looked up in wordRanks
+var maxWordLen = func() int {
+	longest := 0
+	for _, word := range commonWords {
+		longest = max(longest, len(word))
+	}
+	return longest
+}()
+
+// keyboardRows are the rows of a US QWERTY keyboard, unshifted and shifted
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+	"~!@#$%^&*()_+",
+	"qwertyuiop{}|",
+	"asdfghjkl:\"",
+	"zxcvbnm<>?",
+}
+
+// leet maps common character substitutions back to letters
+var leet = map[rune]rune{
+	'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '9': 'g',
+	'1': 'i', '!': 'i', '|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't',
+	'+': 't', '2': 'z',
+}
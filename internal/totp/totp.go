@@ -0,0 +1,166 @@
+// Package totp implements HOTP (RFC 4226) and TOTP (RFC 6238) one-time
+// passwords for generating MFA codes without a phone
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+)
+
+// Algorithm is the HMAC hash used to derive codes
+type Algorithm string
+
+// Supported algorithms
+const (
+	SHA1   Algorithm = "SHA1"
+	SHA256 Algorithm = "SHA256"
+	SHA512 Algorithm = "SHA512"
+)
+
+// Config describes how codes are generated. The zero value is the common
+// authenticator-app setup used by AWS virtual MFA devices: SHA1, six digits
+// and a 30 second period.
+type Config struct {
+	Algorithm Algorithm
+	Digits    int
+	Period    time.Duration
+	// Skew is the number of periods either side of now accepted by Validate
+	Skew int
+}
+
+// withDefaults fills unset fields and checks the rest
+func (c Config) withDefaults() (Config, error) {
+	if c.Algorithm == "" {
+		c.Algorithm = SHA1
+	}
+	if c.Digits == 0 {
+		c.Digits = 6
+	}
+	if c.Period == 0 {
+		c.Period = 30 * time.Second
+	}
+
+	if _, err := c.Algorithm.hash(); err != nil {
+		return c, err
+	}
+	if c.Digits < 6 || c.Digits > 10 {
+		return c, fmt.Errorf("digits must be between 6 and 10, got %d", c.Digits)
+	}
+	if c.Period < time.Second {
+		return c, fmt.Errorf("period must be at least 1s, got %s", c.Period)
+	}
+	if c.Skew < 0 {
+		return c, fmt.Errorf("skew must not be negative")
+	}
+	return c, nil
+}
+
+func (a Algorithm) hash() (func() hash.Hash, error) {
+	switch Algorithm(strings.ToUpper(string(a))) {
+	case SHA1:
+		return sha1.New, nil
+	case SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q (use SHA1, SHA256 or SHA512)", a)
+	}
+}
+
+// HOTP returns the RFC 4226 code for counter
+func HOTP(secret []byte, counter uint64, digits int, algorithm Algorithm) (string, error) {
+	cfg, err := Config{Algorithm: algorithm, Digits: digits}.withDefaults()
+	if err != nil {
+		return "", err
+	}
+	return hotp(secret, counter, cfg), nil
+}
+
+func hotp(secret []byte, counter uint64, cfg Config) string {
+	newHash, _ := cfg.Algorithm.hash()
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(newHash, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulus := uint64(1)
+	for i := 0; i < cfg.Digits; i++ {
+		modulus *= 10
+	}
+	return fmt.Sprintf("%0*d", cfg.Digits, uint64(value)%modulus)
+}
+
+// Generate returns the RFC 6238 code for t
+func Generate(secret []byte, t time.Time, cfg Config) (string, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return "", err
+	}
+	return hotp(secret, counterAt(t, cfg.Period), cfg), nil
+}
+
+// Validate reports whether code is valid at t within the configured skew
+func Validate(secret []byte, code string, t time.Time, cfg Config) (bool, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return false, err
+	}
+
+	counter := counterAt(t, cfg.Period)
+	valid := false
+	for i := -cfg.Skew; i <= cfg.Skew; i++ {
+		if int64(counter)+int64(i) < 0 {
+			continue
+		}
+		candidate := hotp(secret, uint64(int64(counter)+int64(i)), cfg)
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(code)) == 1 {
+			valid = true
+		}
+	}
+	return valid, nil
+}
+
+// Remaining returns how long the code for t stays current
+func Remaining(t time.Time, period time.Duration) time.Duration {
+	if period == 0 {
+		period = 30 * time.Second
+	}
+	elapsed := time.Duration(t.UnixNano()) % period
+	return period - elapsed
+}
+
+func counterAt(t time.Time, period time.Duration) uint64 {
+	return uint64(t.Unix() / int64(period/time.Second))
+}
+
+// DecodeSecret decodes a Base32 seed as shown by authenticator setup
+// screens, tolerating spaces, lower case and missing padding
+func DecodeSecret(seed string) ([]byte, error) {
+	cleaned := strings.ToUpper(strings.Join(strings.Fields(seed), ""))
+	cleaned = strings.TrimRight(cleaned, "=")
+
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(cleaned)
+	if err != nil {
+		return nil, fmt.Errorf("seed is not valid Base32")
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("seed is empty")
+	}
+	return secret, nil
+}
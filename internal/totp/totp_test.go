@@ -0,0 +1,109 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// RFC 4226 appendix D
+func TestHOTPVectors(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	for counter, code := range want {
+		got, err := HOTP(secret, uint64(counter), 6, SHA1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != code {
+			t.Errorf("HOTP(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+// RFC 6238 appendix B
+func TestTOTPVectors(t *testing.T) {
+	secrets := map[Algorithm][]byte{
+		SHA1:   []byte("12345678901234567890"),
+		SHA256: []byte("12345678901234567890123456789012"),
+		SHA512: []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+
+	tests := []struct {
+		unix int64
+		want map[Algorithm]string
+	}{
+		{59, map[Algorithm]string{SHA1: "94287082", SHA256: "46119246", SHA512: "90693936"}},
+		{1111111109, map[Algorithm]string{SHA1: "07081804", SHA256: "68084774", SHA512: "25091201"}},
+		{1111111111, map[Algorithm]string{SHA1: "14050471", SHA256: "67062674", SHA512: "99943326"}},
+		{1234567890, map[Algorithm]string{SHA1: "89005924", SHA256: "91819424", SHA512: "93441116"}},
+		{2000000000, map[Algorithm]string{SHA1: "69279037", SHA256: "90698825", SHA512: "38618901"}},
+		{20000000000, map[Algorithm]string{SHA1: "65353130", SHA256: "77737706", SHA512: "47863826"}},
+	}
+
+	for _, tc := range tests {
+		for algorithm, want := range tc.want {
+			got, err := Generate(secrets[algorithm], time.Unix(tc.unix, 0), Config{Algorithm: algorithm, Digits: 8})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("Generate(%s, %d) = %s, want %s", algorithm, tc.unix, got, want)
+			}
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1234567890, 0)
+
+	previous, _ := Generate(secret, now.Add(-30*time.Second), Config{})
+	stale, _ := Generate(secret, now.Add(-90*time.Second), Config{})
+
+	if ok, _ := Validate(secret, previous, now, Config{}); ok {
+		t.Error("previous code accepted without skew")
+	}
+	if ok, _ := Validate(secret, previous, now, Config{Skew: 1}); !ok {
+		t.Error("previous code rejected with a skew of 1")
+	}
+	if ok, _ := Validate(secret, stale, now, Config{Skew: 1}); ok {
+		t.Error("code three periods old accepted with a skew of 1")
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	bad := []Config{
+		{Algorithm: "MD5"},
+		{Digits: 5},
+		{Digits: 11},
+		{Period: time.Millisecond},
+		{Skew: -1},
+	}
+	for _, cfg := range bad {
+		if _, err := Generate(secret, time.Now(), cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
+
+func TestDecodeSecret(t *testing.T) {
+	secret, err := DecodeSecret("gezd gnbv gy3t qojq gezd gnbv gy3t qojq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret) != "12345678901234567890" {
+		t.Errorf("DecodeSecret() = %q", secret)
+	}
+
+	if _, err := DecodeSecret("not base32!"); err == nil {
+		t.Error("expected an error for an invalid seed")
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	if got := Remaining(time.Unix(59, 0), 30*time.Second); got != time.Second {
+		t.Errorf("Remaining() = %s, want 1s", got)
+	}
+}